@@ -39,8 +39,7 @@ func (a *Account) unAuthHandler(ctx *Context) {
 	if a.UnAuthHandler != nil {
 		a.UnAuthHandler(ctx)
 	} else {
-		ctx.Data(http.StatusUnauthorized, nil)
-		ctx.Abort()
+		ctx.AbortWithStatus(http.StatusUnauthorized)
 	}
 }
 
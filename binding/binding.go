@@ -1,13 +1,105 @@
 package binding
 
-import "net/http"
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// 常用的Content-Type
+const (
+	MIMEJSON              = "application/json"
+	MIMENDJSON            = "application/x-ndjson"
+	MIMEXML               = "application/xml"
+	MIMEXML2              = "text/xml"
+	MIMEPOSTForm          = "application/x-www-form-urlencoded"
+	MIMEMultipartPOSTForm = "multipart/form-data"
+	MIMEYAML              = "application/yaml"
+	MIMEYAML2             = "application/x-yaml"
+	MIMEYAML3             = "text/yaml"
+	MIMETOML              = "application/toml"
+	MIMEMsgPack           = "application/msgpack"
+	MIMEMsgPack2          = "application/x-msgpack"
+)
 
 type Binding interface {
 	Name() string
 	Bind(r *http.Request, obj any) error
 }
 
+// BindingBody 支持直接从已读取的请求体绑定的Binding，可配合请求体缓存多次绑定
+type BindingBody interface {
+	Binding
+	BindBody(body []byte, obj any) error
+}
+
 var (
-	JSONBind = &jsonBinding{}
-	XMLBind  = &xmlBinding{}
+	JSONBind      = &jsonBinding{}
+	XMLBind       = &xmlBinding{}
+	YAMLBind      = &yamlBinding{}
+	TOMLBind      = &tomlBinding{}
+	MsgPackBind   = &msgpackBinding{}
+	QueryBind     = &queryBinding{}
+	FormBind      = &formBinding{}
+	MultipartBind = &multipartBinding{}
+	URIBind       = &uriBinding{}
+	HeaderBind    = &headerBinding{}
 )
+
+// ErrUnsupportedMediaType 没有与请求Content-Type对应的Binding
+var ErrUnsupportedMediaType = errors.New("binding: unsupported media type")
+
+// UnsupportedMediaTypeError 携带具体Content-Type的ErrUnsupportedMediaType
+type UnsupportedMediaTypeError struct {
+	ContentType string
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	return ErrUnsupportedMediaType.Error() + ": " + e.ContentType
+}
+
+func (e *UnsupportedMediaTypeError) Is(target error) bool {
+	return target == ErrUnsupportedMediaType
+}
+
+// Default 根据请求方法和Content-Type选择Binding，没有请求体的请求使用QueryBind，无法匹配时返回nil
+func Default(method string, contentType string) Binding {
+	mediaType := parseMediaType(contentType)
+	if mediaType == "" {
+		switch method {
+		case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions:
+			return QueryBind
+		}
+		return nil
+	}
+
+	switch {
+	case mediaType == MIMEJSON || strings.HasSuffix(mediaType, "+json"):
+		return JSONBind
+	case mediaType == MIMEXML || mediaType == MIMEXML2 || strings.HasSuffix(mediaType, "+xml"):
+		return XMLBind
+	case mediaType == MIMEYAML || mediaType == MIMEYAML2 || mediaType == MIMEYAML3 || strings.HasSuffix(mediaType, "+yaml"):
+		return YAMLBind
+	case mediaType == MIMETOML:
+		return TOMLBind
+	case mediaType == MIMEMsgPack || mediaType == MIMEMsgPack2:
+		return MsgPackBind
+	case mediaType == MIMEPOSTForm:
+		return FormBind
+	case mediaType == MIMEMultipartPOSTForm:
+		return MultipartBind
+	default:
+		return nil
+	}
+}
+
+// 去除Content-Type中的参数并转为小写
+func parseMediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(contentType, ";")
+	}
+
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
@@ -0,0 +1,47 @@
+package binding
+
+import (
+	"bytes"
+	"github.com/fangnan700/PoliteDog/codec"
+	"io"
+	"net/http"
+)
+
+// CodecBinding 基于编解码器解码请求体的Binding，Context会替换为引擎中按媒体类型注册的编解码器
+type CodecBinding interface {
+	BindingBody
+	MediaType() string
+	WithCodec(c codec.Codec) CodecBinding
+}
+
+// 读取请求体并放回，再交给BindBody解码
+func bindRequest(r *http.Request, b BindingBody, obj any) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return b.BindBody(body, obj)
+}
+
+// 使用编解码器解码请求体并校验，c为nil时使用def，类型错误会转换为带有字段路径的BindError
+func decodeBody(c codec.Codec, def codec.Codec, body []byte, obj any, disallowUnknownFields bool) error {
+	if c == nil {
+		c = def
+	}
+
+	decoder := c.NewDecoder(bytes.NewReader(body))
+	// 是否校验对应结构体字段，仅对支持的解码器生效
+	if disallowUnknownFields {
+		if d, ok := decoder.(interface{ DisallowUnknownFields() }); ok {
+			d.DisallowUnknownFields()
+		}
+	}
+
+	if err := decoder.Decode(obj); err != nil {
+		return decodeError(body, err)
+	}
+
+	return validate(obj)
+}
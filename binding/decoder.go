@@ -0,0 +1,32 @@
+package binding
+
+import (
+	"reflect"
+	"sync"
+)
+
+// 自定义类型的解码器，用于query、表单、路由参数和请求头等以字符串传递的数据
+var (
+	decodersMu sync.RWMutex
+	decoders   = make(map[reflect.Type]func(val string) (reflect.Value, error))
+)
+
+// RegisterDecoder 注册将字符串转换为T的解码器，例如decimal、uuid等类型，
+// 优先于encoding.TextUnmarshaler，同一类型会被覆盖
+func RegisterDecoder[T any](fn func(val string) (T, error)) {
+	decodersMu.Lock()
+	decoders[reflect.TypeOf((*T)(nil)).Elem()] = func(val string) (reflect.Value, error) {
+		v, err := fn(val)
+		return reflect.ValueOf(&v).Elem(), err
+	}
+	decodersMu.Unlock()
+}
+
+// 获取类型对应的自定义解码器
+func lookupDecoder(t reflect.Type) (func(val string) (reflect.Value, error), bool) {
+	decodersMu.RLock()
+	fn, ok := decoders[t]
+	decodersMu.RUnlock()
+
+	return fn, ok
+}
@@ -0,0 +1,133 @@
+package binding
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fangnan700/PoliteDog/codec"
+	"strconv"
+)
+
+// BindError 请求数据无法转换为目标字段时的错误，Path为字段路径，如items[3].price
+type BindError struct {
+	Path  string `json:"field"`
+	Value any    `json:"value,omitempty"`
+	Err   error  `json:"-"`
+}
+
+func (e *BindError) Error() string {
+	if e.Value == nil {
+		return fmt.Sprintf("binding: field '%s': %v", e.Path, e.Err)
+	}
+
+	return fmt.Sprintf("binding: field '%s': invalid value %v: %v", e.Path, describeValue(e.Value), e.Err)
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+func describeValue(v any) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+
+	return fmt.Sprint(v)
+}
+
+// 为解码错误补充字段路径和原始值，JSON根据错误位置在请求体中定位字段
+func decodeError(body []byte, err error) error {
+	var typeErr *json.UnmarshalTypeError
+	var unmarshalErr *codec.UnmarshalError
+
+	switch {
+	case errors.As(err, &typeErr):
+		path, value, ok := jsonPathAt(body, typeErr.Offset)
+		if !ok {
+			path = typeErr.Field
+		}
+		return &BindError{Path: path, Value: value, Err: err}
+	case errors.As(err, &unmarshalErr):
+		return &BindError{Path: unmarshalErr.Path, Value: unmarshalErr.Value, Err: err}
+	default:
+		return err
+	}
+}
+
+// JSON中正在解析的对象或数组
+type jsonFrame struct {
+	path    string
+	isArray bool
+	index   int
+	key     string
+	wantKey bool
+}
+
+func (f *jsonFrame) childPath() string {
+	if f.isArray {
+		return f.path + "[" + strconv.Itoa(f.index) + "]"
+	}
+	if f.path == "" {
+		return f.key
+	}
+
+	return f.path + "." + f.key
+}
+
+// 逐个读取token，找到在offset处结束的值，返回其路径和内容
+func jsonPathAt(body []byte, offset int64) (string, any, bool) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var stack []*jsonFrame
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", nil, false
+		}
+
+		var top *jsonFrame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		// 对象的键
+		if top != nil && !top.isArray && top.wantKey {
+			if key, ok := tok.(string); ok {
+				top.key, top.wantKey = key, false
+				continue
+			}
+		}
+
+		// 容器结束，父级对象等待下一个键
+		if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 && !stack[len(stack)-1].isArray {
+				stack[len(stack)-1].wantKey = true
+			}
+			continue
+		}
+
+		path := ""
+		if top != nil {
+			if top.isArray {
+				top.index++
+			}
+			path = top.childPath()
+		}
+
+		if dec.InputOffset() >= offset {
+			if _, ok := tok.(json.Delim); ok {
+				return path, nil, true
+			}
+			return path, tok, true
+		}
+
+		if d, ok := tok.(json.Delim); ok {
+			stack = append(stack, &jsonFrame{path: path, isArray: d == '[', index: -1, wantKey: d == '{'})
+		} else if top != nil && !top.isArray {
+			top.wantKey = true
+		}
+	}
+}
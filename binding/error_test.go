@@ -0,0 +1,39 @@
+package binding
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJSONBind_ErrorPath(t *testing.T) {
+	type item struct {
+		Name  string  `json:"name"`
+		Price float64 `json:"price"`
+	}
+	type order struct {
+		ID    int    `json:"id"`
+		Items []item `json:"items"`
+		Tags  []int  `json:"tags"`
+	}
+
+	cases := []struct {
+		body  string
+		path  string
+		value any
+	}{
+		{`{"id":"x"}`, "id", "x"},
+		{`{"id":1,"items":[{"name":"a","price":1},{"name":"b"},{"price":"9.5"}]}`, "items[2].price", "9.5"},
+		{`{"tags":[1,[2]],"id":1}`, "tags[1]", nil},
+		{`{"items":{"name":"a"}}`, "items", nil},
+	}
+
+	for _, tc := range cases {
+		var o order
+		err := JSONBind.BindBody([]byte(tc.body), &o)
+
+		var be *BindError
+		if !errors.As(err, &be) || be.Path != tc.path || be.Value != tc.value {
+			t.Errorf("%s: err = %#v", tc.body, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package binding
+
+import (
+	"errors"
+	"net/http"
+)
+
+// 解析multipart表单时默认使用的最大内存
+const defaultMultipartMemory = 32 << 20
+
+type queryBinding struct {
+}
+
+func (q *queryBinding) Name() string {
+	return "query"
+}
+
+func (q *queryBinding) Bind(r *http.Request, obj any) error {
+	if err := mapForm(obj, r.URL.Query(), "form"); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+type formBinding struct {
+}
+
+func (f *formBinding) Name() string {
+	return "form"
+}
+
+// Bind 绑定query和表单参数，同名时表单参数在前
+func (f *formBinding) Bind(r *http.Request, obj any) error {
+	if err := parseForm(r); err != nil {
+		return err
+	}
+
+	if err := mapForm(obj, r.Form, "form"); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+// 解析普通表单和multipart表单，已解析过时不会重复读取请求体
+func parseForm(r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+
+	err := r.ParseMultipartForm(defaultMultipartMemory)
+	if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,361 @@
+package binding
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errNotPointer = errors.New("binding: obj must be a non-nil pointer")
+
+var errNotStruct = errors.New("binding: obj must be a pointer to a struct")
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// 按结构体标签从键值对中取值的数据源
+type valueSource interface {
+	lookup(key string) ([]string, bool)
+}
+
+// 表单、query等以原始键名取值的数据源
+type formSource map[string][]string
+
+func (fs formSource) lookup(key string) ([]string, bool) {
+	vals, ok := fs[key]
+	return vals, ok
+}
+
+// 将表单形式的数据按标签映射到结构体，tag为form、uri、header等
+func mapForm(obj any, form map[string][]string, tag string) error {
+	return mapSource(obj, formSource(form), tag)
+}
+
+func mapSource(obj any, source valueSource, tag string) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errNotPointer
+	}
+	if rv.Elem().Kind() != reflect.Struct {
+		return errNotStruct
+	}
+
+	_, err := mapStruct(rv.Elem(), source, tag)
+	return err
+}
+
+// 映射结构体的各个字段，返回是否有字段被设置
+func mapStruct(rv reflect.Value, source valueSource, tag string) (bool, error) {
+	rt := rv.Type()
+	isSet := false
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() && !sf.Anonymous {
+			continue
+		}
+
+		name, _, _ := strings.Cut(sf.Tag.Get(tag), ",")
+		if name == "-" {
+			continue
+		}
+
+		// 未设置标签的嵌套结构体与外层共用同一组键
+		if name == "" {
+			if isNestedStruct(sf.Type) && !isFileField(sf.Type) {
+				ok, err := mapNested(rv.Field(i), source, tag)
+				if err != nil {
+					return false, err
+				}
+				isSet = isSet || ok
+				continue
+			}
+			if !sf.IsExported() {
+				continue
+			}
+			name = sf.Name
+		}
+
+		if isFileField(sf.Type) {
+			if fs, ok := source.(fileSource); ok {
+				files, found := fs.lookupFiles(name)
+				setFileField(rv.Field(i), files)
+				isSet = isSet || found
+			}
+			continue
+		}
+
+		// 缺少的值使用default标签，默认值不影响嵌套指针结构体的分配
+		vals, ok := source.lookup(name)
+		if !ok {
+			def, hasDefault := sf.Tag.Lookup("default")
+			if !hasDefault {
+				continue
+			}
+			vals = defaultValues(rv.Field(i), def)
+		}
+
+		if err := setField(rv.Field(i), vals, sf, name); err != nil {
+			return false, err
+		}
+		isSet = isSet || ok
+	}
+
+	return isSet, nil
+}
+
+// 映射嵌套结构体，指针类型仅在有字段被设置时才分配
+func mapNested(field reflect.Value, source valueSource, tag string) (bool, error) {
+	if field.Kind() != reflect.Pointer {
+		return mapStruct(field, source, tag)
+	}
+	if !field.CanSet() {
+		return false, nil
+	}
+
+	elem := reflect.New(field.Type().Elem())
+	ok, err := mapNested(elem.Elem(), source, tag)
+	if ok && err == nil {
+		field.Set(elem)
+	}
+
+	return ok, err
+}
+
+// 判断类型是否为需要展开映射的嵌套结构体
+func isNestedStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if _, ok := lookupDecoder(t); ok {
+		return false
+	}
+
+	return t.Kind() == reflect.Struct && t != timeType && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// 解析default标签，切片和数组使用逗号分隔多个值
+func defaultValues(field reflect.Value, def string) []string {
+	t := field.Type()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && !hasScalarDecoder(t) {
+		return strings.Split(def, ",")
+	}
+
+	return []string{def}
+}
+
+// 判断切片等类型是否作为单个值解码，如实现了TextUnmarshaler的net.IP或注册了解码器的类型
+func hasScalarDecoder(t reflect.Type) bool {
+	if _, ok := lookupDecoder(t); ok {
+		return true
+	}
+
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// 根据字段类型设置取到的值，切片和数组使用全部值，其它类型使用第一个值，
+// 转换失败时返回带有字段路径和原始值的BindError
+func setField(field reflect.Value, vals []string, sf reflect.StructField, path string) error {
+	if len(vals) == 0 {
+		return nil
+	}
+
+	if field.Kind() == reflect.Pointer && !hasScalarDecoder(field.Type()) {
+		elem := reflect.New(field.Type().Elem())
+		if err := setField(elem.Elem(), vals, sf, path); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	if !hasScalarDecoder(field.Type()) {
+		switch field.Kind() {
+		case reflect.Slice:
+			slice := reflect.MakeSlice(field.Type(), len(vals), len(vals))
+			for i, val := range vals {
+				if err := setValue(slice.Index(i), val, sf); err != nil {
+					return &BindError{Path: fmt.Sprintf("%s[%d]", path, i), Value: val, Err: err}
+				}
+			}
+			field.Set(slice)
+			return nil
+		case reflect.Array:
+			if len(vals) != field.Len() {
+				return &BindError{Path: path, Value: vals, Err: fmt.Errorf("expected %d values for %s", field.Len(), field.Type())}
+			}
+			for i, val := range vals {
+				if err := setValue(field.Index(i), val, sf); err != nil {
+					return &BindError{Path: fmt.Sprintf("%s[%d]", path, i), Value: val, Err: err}
+				}
+			}
+			return nil
+		}
+	}
+
+	if err := setValue(field, vals[0], sf); err != nil {
+		return &BindError{Path: path, Value: vals[0], Err: err}
+	}
+
+	return nil
+}
+
+// 判断字段是否实现了encoding.TextUnmarshaler
+func isTextValue(field reflect.Value) bool {
+	return field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType)
+}
+
+// 将单个字符串值转换为字段类型
+func setValue(field reflect.Value, val string, sf reflect.StructField) error {
+	if decode, ok := lookupDecoder(field.Type()); ok {
+		v, err := decode(val)
+		if err != nil {
+			return err
+		}
+		field.Set(v)
+		return nil
+	}
+
+	if field.Kind() == reflect.Pointer {
+		elem := reflect.New(field.Type().Elem())
+		if err := setValue(elem.Elem(), val, sf); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	// time.Time同样实现了TextUnmarshaler，需要优先处理以支持time_format
+	switch field.Type() {
+	case timeType:
+		return setTime(field, val, sf)
+	case durationType:
+		if val == "" {
+			field.SetInt(0)
+			return nil
+		}
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	if isTextValue(field) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(val)
+	case reflect.Bool:
+		if val == "" {
+			field.SetBool(false)
+			return nil
+		}
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if val == "" {
+			field.SetInt(0)
+			return nil
+		}
+		i, err := strconv.ParseInt(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if val == "" {
+			field.SetUint(0)
+			return nil
+		}
+		u, err := strconv.ParseUint(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		if val == "" {
+			field.SetFloat(0)
+			return nil
+		}
+		f, err := strconv.ParseFloat(val, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Interface:
+		field.Set(reflect.ValueOf(val))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return nil
+}
+
+// 解析时间，time_format支持Go的时间布局以及unix、unixmilli、unixnano，默认为RFC3339；
+// time_utc为true时使用UTC，time_location指定时区
+func setTime(field reflect.Value, val string, sf reflect.StructField) error {
+	if val == "" {
+		field.Set(reflect.ValueOf(time.Time{}))
+		return nil
+	}
+
+	layout := sf.Tag.Get("time_format")
+	if layout == "" {
+		layout = time.RFC3339
+	}
+
+	switch strings.ToLower(layout) {
+	case "unix", "unixmilli", "unixnano":
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return err
+		}
+
+		var t time.Time
+		switch strings.ToLower(layout) {
+		case "unix":
+			t = time.Unix(n, 0)
+		case "unixmilli":
+			t = time.UnixMilli(n)
+		default:
+			t = time.Unix(0, n)
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	loc := time.Local
+	if utc, _ := strconv.ParseBool(sf.Tag.Get("time_utc")); utc {
+		loc = time.UTC
+	}
+	if name := sf.Tag.Get("time_location"); name != "" {
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return err
+		}
+		loc = l
+	}
+
+	t, err := time.ParseInLocation(layout, val, loc)
+	if err != nil {
+		return err
+	}
+
+	field.Set(reflect.ValueOf(t))
+	return nil
+}
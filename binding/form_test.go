@@ -0,0 +1,125 @@
+package binding
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type formPage struct {
+	Page int `form:"page"`
+	Size int `form:"size"`
+}
+
+type formFilter struct {
+	formPage
+	Keyword string    `form:"q" binding:"required"`
+	Tags    []string  `form:"tag"`
+	Score   *float64  `form:"score"`
+	Active  bool      `form:"active"`
+	Since   time.Time `form:"since" time_format:"2006-01-02" time_utc:"true"`
+	IP      net.IP    `form:"ip"`
+	Ignored string    `form:"-"`
+	Owner   *formOwner
+}
+
+type formOwner struct {
+	OwnerID uint64 `form:"owner_id"`
+}
+
+func TestQueryBind(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet,
+		"/?q=dog&page=2&tag=a&tag=b&score=9.5&active=true&since=2024-02-17&ip=10.0.0.1&owner_id=7&Ignored=x", nil)
+
+	var filter formFilter
+	if err := QueryBind.Bind(r, &filter); err != nil {
+		t.Fatal(err)
+	}
+
+	if filter.Keyword != "dog" || filter.Page != 2 || len(filter.Tags) != 2 || filter.Tags[1] != "b" {
+		t.Errorf("unexpected result: %+v", filter)
+	}
+	if filter.Score == nil || *filter.Score != 9.5 || !filter.Active {
+		t.Errorf("unexpected score/active: %+v", filter)
+	}
+	if !filter.Since.Equal(time.Date(2024, 2, 17, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Since = %v", filter.Since)
+	}
+	if !filter.IP.Equal(net.ParseIP("10.0.0.1")) {
+		t.Errorf("IP = %v", filter.IP)
+	}
+	if filter.Owner == nil || filter.Owner.OwnerID != 7 || filter.Ignored != "" {
+		t.Errorf("unexpected owner/ignored: %+v", filter)
+	}
+}
+
+func TestFormBind(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/?page=3", strings.NewReader("q=cat&size=bad"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var filter formFilter
+	if err := FormBind.Bind(r, &filter); err == nil || !strings.Contains(err.Error(), "size") {
+		t.Errorf("Bind() error = %v, want error for size", err)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("page=1"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if err := FormBind.Bind(r, &formFilter{}); err == nil {
+		t.Error("Bind() without required q returned nil error")
+	}
+}
+
+type money struct {
+	cents int64
+}
+
+func TestQueryBind_DefaultAndDecoder(t *testing.T) {
+	RegisterDecoder(func(val string) (money, error) {
+		f, err := strconv.ParseFloat(val, 64)
+		return money{cents: int64(math.Round(f * 100))}, err
+	})
+
+	var q struct {
+		Page   int      `form:"page" default:"1"`
+		Size   *int     `form:"size" default:"20"`
+		Sort   []string `form:"sort" default:"id,name"`
+		Price  money    `form:"price"`
+		Prices []money  `form:"prices"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?page=3&price=9.99&prices=1&prices=2.5", nil)
+	if err := QueryBind.Bind(r, &q); err != nil {
+		t.Fatal(err)
+	}
+
+	if q.Page != 3 || q.Size == nil || *q.Size != 20 || len(q.Sort) != 2 || q.Sort[1] != "name" {
+		t.Errorf("unexpected defaults: %+v", q)
+	}
+	if q.Price.cents != 999 || len(q.Prices) != 2 || q.Prices[1].cents != 250 {
+		t.Errorf("unexpected prices: %+v", q)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/?prices=1&prices=x", nil)
+	err := QueryBind.Bind(r, &q)
+	var be *BindError
+	if !errors.As(err, &be) || be.Path != "prices[1]" || be.Value != "x" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestQueryBind_NotStruct(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/?page=2", nil)
+	var m map[string]string
+	var n int
+	for _, obj := range []any{&m, &n} {
+		if err := QueryBind.Bind(r, obj); !errors.Is(err, errNotStruct) {
+			t.Errorf("Bind(%T) = %v, want errNotStruct", obj, err)
+		}
+	}
+}
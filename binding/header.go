@@ -0,0 +1,29 @@
+package binding
+
+import (
+	"net/http"
+	"net/textproto"
+)
+
+// 请求头数据源，键名不区分大小写
+type headerSource http.Header
+
+func (hs headerSource) lookup(key string) ([]string, bool) {
+	vals, ok := hs[textproto.CanonicalMIMEHeaderKey(key)]
+	return vals, ok
+}
+
+type headerBinding struct {
+}
+
+func (h *headerBinding) Name() string {
+	return "header"
+}
+
+func (h *headerBinding) Bind(r *http.Request, obj any) error {
+	if err := mapSource(obj, headerSource(r.Header), "header"); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
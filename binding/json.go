@@ -1,32 +1,36 @@
 package binding
 
 import (
-	"bytes"
-	"encoding/json"
-	"io"
+	"github.com/fangnan700/PoliteDog/codec"
 	"net/http"
 )
 
 type jsonBinding struct {
+	Codec                 codec.Codec // 为nil时使用codec.JSON
 	DisallowUnknownFields bool
 }
 
+// NewJSONBinding 创建独立的JSON Binding，避免修改共享的JSONBind
+func NewJSONBinding(c codec.Codec, disallowUnknownFields bool) CodecBinding {
+	return &jsonBinding{Codec: c, DisallowUnknownFields: disallowUnknownFields}
+}
+
 func (j *jsonBinding) Name() string {
 	return "json"
 }
 
+func (j *jsonBinding) MediaType() string {
+	return MIMEJSON
+}
+
+func (j *jsonBinding) WithCodec(c codec.Codec) CodecBinding {
+	return &jsonBinding{Codec: c, DisallowUnknownFields: j.DisallowUnknownFields}
+}
+
 func (j *jsonBinding) Bind(r *http.Request, obj any) error {
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		return err
-	}
-
-	r.Body = io.NopCloser(bytes.NewReader(body))
-	decoder := json.NewDecoder(bytes.NewReader(body))
-	// 是否校验json对应结构体字段
-	if j.DisallowUnknownFields {
-		decoder.DisallowUnknownFields()
-	}
-
-	return decoder.Decode(obj)
+	return bindRequest(r, j, obj)
+}
+
+func (j *jsonBinding) BindBody(body []byte, obj any) error {
+	return decodeBody(j.Codec, codec.JSON, body, obj, j.DisallowUnknownFields)
 }
@@ -0,0 +1,30 @@
+package binding
+
+import (
+	"github.com/fangnan700/PoliteDog/codec"
+	"net/http"
+)
+
+type msgpackBinding struct {
+	Codec codec.Codec // 为nil时使用codec.MsgPack
+}
+
+func (m *msgpackBinding) Name() string {
+	return "msgpack"
+}
+
+func (m *msgpackBinding) MediaType() string {
+	return MIMEMsgPack
+}
+
+func (m *msgpackBinding) WithCodec(c codec.Codec) CodecBinding {
+	return &msgpackBinding{Codec: c}
+}
+
+func (m *msgpackBinding) Bind(r *http.Request, obj any) error {
+	return bindRequest(r, m, obj)
+}
+
+func (m *msgpackBinding) BindBody(body []byte, obj any) error {
+	return decodeBody(m.Codec, codec.MsgPack, body, obj, false)
+}
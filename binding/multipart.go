@@ -0,0 +1,69 @@
+package binding
+
+import (
+	"mime/multipart"
+	"net/http"
+	"reflect"
+)
+
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeaderSliceType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+type multipartBinding struct {
+}
+
+func (m *multipartBinding) Name() string {
+	return "multipart"
+}
+
+// Bind 绑定multipart表单，文本字段和*multipart.FileHeader、[]*multipart.FileHeader类型的文件字段一并绑定
+func (m *multipartBinding) Bind(r *http.Request, obj any) error {
+	if err := r.ParseMultipartForm(defaultMultipartMemory); err != nil {
+		return err
+	}
+
+	if err := mapSource(obj, (*multipartSource)(r.MultipartForm), "form"); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+// multipart表单数据源，同时提供文本值和文件
+type multipartSource multipart.Form
+
+func (ms *multipartSource) lookup(key string) ([]string, bool) {
+	vals, ok := ms.Value[key]
+	return vals, ok
+}
+
+func (ms *multipartSource) lookupFiles(key string) ([]*multipart.FileHeader, bool) {
+	files, ok := ms.File[key]
+	return files, ok
+}
+
+// 提供上传文件的数据源
+type fileSource interface {
+	lookupFiles(key string) ([]*multipart.FileHeader, bool)
+}
+
+// 判断字段是否为文件类型
+func isFileField(t reflect.Type) bool {
+	return t == fileHeaderType || t == fileHeaderSliceType
+}
+
+// 设置文件字段
+func setFileField(field reflect.Value, files []*multipart.FileHeader) {
+	if len(files) == 0 {
+		return
+	}
+
+	if field.Type() == fileHeaderType {
+		field.Set(reflect.ValueOf(files[0]))
+		return
+	}
+
+	field.Set(reflect.ValueOf(files))
+}
@@ -0,0 +1,59 @@
+package binding
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type profileForm struct {
+	Nickname string                  `form:"nickname" binding:"required"`
+	Avatar   *multipart.FileHeader   `form:"avatar" binding:"required"`
+	Photos   []*multipart.FileHeader `form:"photos"`
+}
+
+func newMultipartRequest(t *testing.T, fields map[string]string, files map[string][]string) *http.Request {
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	for key, value := range fields {
+		if err := mw.WriteField(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for key, names := range files {
+		for _, name := range names {
+			fw, err := mw.CreateFormFile(key, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _ = fw.Write([]byte("content of " + name))
+		}
+	}
+	_ = mw.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/", body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+func TestMultipartBind(t *testing.T) {
+	r := newMultipartRequest(t,
+		map[string]string{"nickname": "dog"},
+		map[string][]string{"avatar": {"a.png"}, "photos": {"1.jpg", "2.jpg"}},
+	)
+
+	var form profileForm
+	if err := MultipartBind.Bind(r, &form); err != nil {
+		t.Fatal(err)
+	}
+	if form.Nickname != "dog" || form.Avatar == nil || form.Avatar.Filename != "a.png" || len(form.Photos) != 2 {
+		t.Errorf("unexpected result: %+v", form)
+	}
+
+	r = newMultipartRequest(t, map[string]string{"nickname": "dog"}, nil)
+	if err := MultipartBind.Bind(r, &profileForm{}); err == nil {
+		t.Error("Bind() without required avatar returned nil error")
+	}
+}
@@ -0,0 +1,128 @@
+package binding
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fangnan700/PoliteDog/codec"
+	"io"
+	"strings"
+)
+
+// ErrTooManyItems 流式绑定的元素数量超过上限
+var ErrTooManyItems = errors.New("binding: too many items in stream")
+
+// StreamDecoder 逐个解码NDJSON或顶层JSON数组中的元素，每次只在内存中保留一个元素
+type StreamDecoder struct {
+	DisallowUnknownFields bool
+
+	dec      *json.Decoder
+	codec    codec.Codec
+	isArray  bool
+	started  bool
+	index    int
+	maxItems int
+}
+
+// NewStreamDecoder 根据Content-Type创建StreamDecoder，application/x-ndjson按行解码，
+// application/json要求顶层为数组。c为nil时使用codec.JSON，maxItems<=0时不限制元素数量
+func NewStreamDecoder(r io.Reader, contentType string, c codec.Codec, maxItems int) (*StreamDecoder, error) {
+	d := &StreamDecoder{dec: json.NewDecoder(r), codec: c, maxItems: maxItems}
+
+	switch mediaType := parseMediaType(contentType); {
+	case mediaType == MIMENDJSON:
+	case mediaType == MIMEJSON || strings.HasSuffix(mediaType, "+json"):
+		d.isArray = true
+	default:
+		return nil, &UnsupportedMediaTypeError{ContentType: contentType}
+	}
+
+	return d, nil
+}
+
+// Next 解码并校验下一个元素，没有更多元素时返回io.EOF，错误中的字段路径以元素下标开头，如[3].price
+func (d *StreamDecoder) Next(obj any) error {
+	if d.isArray {
+		more, err := d.more()
+		if err != nil || !more {
+			return err
+		}
+	}
+
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		return err
+	}
+
+	if d.maxItems > 0 && d.index >= d.maxItems {
+		return ErrTooManyItems
+	}
+
+	index := d.index
+	d.index++
+	if err := decodeBody(d.codec, codec.JSON, raw, obj, d.DisallowUnknownFields); err != nil {
+		return itemError(index, err)
+	}
+
+	return nil
+}
+
+// 判断数组中是否还有元素，读到结尾的]时返回io.EOF
+func (d *StreamDecoder) more() (bool, error) {
+	if !d.started {
+		d.started = true
+		tok, err := d.dec.Token()
+		if err == io.EOF {
+			return false, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return false, err
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return false, errors.New("binding: stream body must be a JSON array")
+		}
+	}
+
+	if d.dec.More() {
+		return true, nil
+	}
+
+	if _, err := d.dec.Token(); err != nil {
+		if err == io.EOF {
+			return false, io.ErrUnexpectedEOF
+		}
+		return false, err
+	}
+
+	return false, io.EOF
+}
+
+// 为元素的错误路径加上下标
+func itemError(index int, err error) error {
+	prefix := fmt.Sprintf("[%d]", index)
+
+	var bindErr *BindError
+	var validationErrs ValidationErrors
+	switch {
+	case errors.As(err, &bindErr):
+		return &BindError{Path: joinItemPath(prefix, bindErr.Path), Value: bindErr.Value, Err: bindErr.Err}
+	case errors.As(err, &validationErrs):
+		errs := make(ValidationErrors, len(validationErrs))
+		for i, fe := range validationErrs {
+			e := *fe
+			e.Field = joinItemPath(prefix, fe.Field)
+			errs[i] = &e
+		}
+		return errs
+	default:
+		return &BindError{Path: prefix, Err: err}
+	}
+}
+
+func joinItemPath(prefix string, path string) string {
+	if path == "" || path[0] == '[' {
+		return prefix + path
+	}
+
+	return prefix + "." + path
+}
@@ -0,0 +1,30 @@
+package binding
+
+import (
+	"github.com/fangnan700/PoliteDog/codec"
+	"net/http"
+)
+
+type tomlBinding struct {
+	Codec codec.Codec // 为nil时使用codec.TOML
+}
+
+func (t *tomlBinding) Name() string {
+	return "toml"
+}
+
+func (t *tomlBinding) MediaType() string {
+	return MIMETOML
+}
+
+func (t *tomlBinding) WithCodec(c codec.Codec) CodecBinding {
+	return &tomlBinding{Codec: c}
+}
+
+func (t *tomlBinding) Bind(r *http.Request, obj any) error {
+	return bindRequest(r, t, obj)
+}
+
+func (t *tomlBinding) BindBody(body []byte, obj any) error {
+	return decodeBody(t.Codec, codec.TOML, body, obj, false)
+}
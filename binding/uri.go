@@ -0,0 +1,34 @@
+package binding
+
+import (
+	"context"
+	"net/http"
+)
+
+type uriParamsKey struct{}
+
+// WithURIParams 将路由参数附加到请求上，供URIBind等Binding读取
+func WithURIParams(r *http.Request, params map[string][]string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), uriParamsKey{}, params))
+}
+
+// URIParams 获取通过WithURIParams附加到请求上的路由参数
+func URIParams(r *http.Request) map[string][]string {
+	params, _ := r.Context().Value(uriParamsKey{}).(map[string][]string)
+	return params
+}
+
+type uriBinding struct {
+}
+
+func (u *uriBinding) Name() string {
+	return "uri"
+}
+
+func (u *uriBinding) Bind(r *http.Request, obj any) error {
+	if err := mapForm(obj, URIParams(r), "uri"); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
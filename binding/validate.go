@@ -0,0 +1,301 @@
+package binding
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StructValidator 结构体校验器，绑定完成后会自动调用
+type StructValidator interface {
+	ValidateStruct(obj any) error
+}
+
+// Validator 绑定使用的校验器，可以替换为自定义实现，设为nil时不校验
+var Validator StructValidator = defaultValidator
+
+var defaultValidator = NewTagValidator()
+
+// RegisterValidation 向默认校验器注册自定义规则
+func RegisterValidation(name string, fn ValidationFunc) {
+	defaultValidator.RegisterValidation(name, fn)
+}
+
+// 使用全局校验器校验绑定结果
+func validate(obj any) error {
+	if Validator == nil {
+		return nil
+	}
+
+	return Validator.ValidateStruct(obj)
+}
+
+// FieldLevel 校验规则的上下文
+type FieldLevel struct {
+	Field  reflect.Value // 当前校验的值
+	Parent reflect.Value // 字段所在的结构体，用于跨字段比较
+	Param  string        // 规则参数，如min=3中的3
+}
+
+// ValidationFunc 校验规则，返回false表示校验失败
+type ValidationFunc func(fl FieldLevel) bool
+
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Param string `json:"param,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+func (e *FieldError) Error() string {
+	if e.Param == "" {
+		return fmt.Sprintf("field '%s' failed on the '%s' rule", e.Field, e.Rule)
+	}
+
+	return fmt.Sprintf("field '%s' failed on the '%s=%s' rule", e.Field, e.Rule, e.Param)
+}
+
+// ValidationErrors 校验错误列表，可以直接作为422响应的内容
+type ValidationErrors []*FieldError
+
+func (ve ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(ve))
+	for _, e := range ve {
+		msgs = append(msgs, e.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// TagValidator 基于binding标签的校验器，例如`binding:"required,min=3,max=64"`
+type TagValidator struct {
+	mu    sync.RWMutex
+	rules map[string]ValidationFunc
+	cache sync.Map // reflect.Type -> []*fieldRules
+}
+
+// NewTagValidator 创建带有内置规则的校验器
+func NewTagValidator() *TagValidator {
+	v := &TagValidator{rules: make(map[string]ValidationFunc)}
+	for name, fn := range builtinRules {
+		v.rules[name] = fn
+	}
+
+	return v
+}
+
+// RegisterValidation 注册自定义规则，同名规则会被覆盖
+func (v *TagValidator) RegisterValidation(name string, fn ValidationFunc) {
+	v.mu.Lock()
+	v.rules[name] = fn
+	v.mu.Unlock()
+}
+
+// ValidateStruct 校验结构体，支持结构体指针以及结构体的切片和map
+func (v *TagValidator) ValidateStruct(obj any) error {
+	var errs ValidationErrors
+	v.validateValue(reflect.ValueOf(obj), "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// 字段上解析好的规则
+type rule struct {
+	name  string
+	param string
+}
+
+type fieldRules struct {
+	index   int
+	name    string
+	rules   []rule // dive之前的规则
+	dive    []rule // dive之后作用于元素的规则
+	hasDive bool
+}
+
+// 解析并缓存结构体的校验标签
+func (v *TagValidator) structRules(t reflect.Type) []*fieldRules {
+	if cached, ok := v.cache.Load(t); ok {
+		return cached.([]*fieldRules)
+	}
+
+	fields := make([]*fieldRules, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tag := sf.Tag.Get("binding")
+		if tag == "-" {
+			continue
+		}
+
+		fr := &fieldRules{index: i, name: sf.Name}
+		if tag != "" {
+			for _, item := range strings.Split(tag, ",") {
+				name, param, _ := strings.Cut(strings.TrimSpace(item), "=")
+				if name == "" {
+					continue
+				}
+				if name == "dive" {
+					fr.hasDive = true
+					continue
+				}
+
+				if fr.hasDive {
+					fr.dive = append(fr.dive, rule{name: name, param: param})
+				} else {
+					fr.rules = append(fr.rules, rule{name: name, param: param})
+				}
+			}
+		}
+		fields = append(fields, fr)
+	}
+
+	v.cache.Store(t, fields)
+	return fields
+}
+
+// 递归校验值，结构体、结构体切片和map中的结构体都会被校验
+func (v *TagValidator) validateValue(rv reflect.Value, path string, errs *ValidationErrors) {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		if rv.Type() == timeType {
+			return
+		}
+		v.validateStruct(rv, path, errs)
+	case reflect.Slice, reflect.Array:
+		if !isStructLike(rv.Type().Elem()) {
+			return
+		}
+		for i := 0; i < rv.Len(); i++ {
+			v.validateValue(rv.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Map:
+		if !isStructLike(rv.Type().Elem()) {
+			return
+		}
+		iter := rv.MapRange()
+		for iter.Next() {
+			v.validateValue(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key().Interface()), errs)
+		}
+	}
+}
+
+func (v *TagValidator) validateStruct(rv reflect.Value, path string, errs *ValidationErrors) {
+	for _, fr := range v.structRules(rv.Type()) {
+		field := rv.Field(fr.index)
+		fieldPath := fr.name
+		if path != "" {
+			fieldPath = path + "." + fr.name
+		}
+
+		if !v.applyRules(field, rv, fr.rules, fieldPath, errs) {
+			continue
+		}
+
+		if fr.hasDive {
+			v.dive(field, rv, fr.dive, fieldPath, errs)
+		}
+
+		v.validateValue(field, fieldPath, errs)
+	}
+}
+
+// 对切片、数组或map中的每个元素应用规则
+func (v *TagValidator) dive(field reflect.Value, parent reflect.Value, rules []rule, path string, errs *ValidationErrors) {
+	field = indirectValue(field)
+
+	switch field.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < field.Len(); i++ {
+			v.applyRules(field.Index(i), parent, rules, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Map:
+		iter := field.MapRange()
+		for iter.Next() {
+			v.applyRules(iter.Value(), parent, rules, fmt.Sprintf("%s[%v]", path, iter.Key().Interface()), errs)
+		}
+	}
+}
+
+// 按顺序应用规则，遇到第一个失败的规则即停止，返回是否全部通过
+func (v *TagValidator) applyRules(field reflect.Value, parent reflect.Value, rules []rule, path string, errs *ValidationErrors) bool {
+	for _, r := range rules {
+		if r.name == "omitempty" {
+			if isZero(field) {
+				return false
+			}
+			continue
+		}
+
+		v.mu.RLock()
+		fn, ok := v.rules[r.name]
+		v.mu.RUnlock()
+		if !ok {
+			panic("binding: undefined validation rule: " + r.name)
+		}
+
+		if !fn(FieldLevel{Field: field, Parent: parent, Param: r.param}) {
+			fe := &FieldError{Field: path, Rule: r.name, Param: r.param}
+			if f := indirectValue(field); f.IsValid() && f.CanInterface() {
+				fe.Value = f.Interface()
+			}
+			*errs = append(*errs, fe)
+			return false
+		}
+	}
+
+	return true
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// 判断类型是否为需要递归校验的结构体
+func isStructLike(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+// 解引用指针和接口
+func indirectValue(rv reflect.Value) reflect.Value {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return reflect.Value{}
+		}
+		rv = rv.Elem()
+	}
+
+	return rv
+}
+
+// 判断值是否为空，nil指针、零值和空容器都视为空
+func isZero(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Pointer, reflect.Interface:
+		return rv.IsNil()
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String, reflect.Chan:
+		return rv.Len() == 0
+	default:
+		return rv.IsZero()
+	}
+}
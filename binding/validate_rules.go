@@ -0,0 +1,221 @@
+package binding
+
+import (
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 内置校验规则
+var builtinRules = map[string]ValidationFunc{
+	"required": func(fl FieldLevel) bool { return !isZero(fl.Field) },
+	"min":      compareParam(func(size, param float64) bool { return size >= param }),
+	"max":      compareParam(func(size, param float64) bool { return size <= param }),
+	"len":      compareParam(func(size, param float64) bool { return size == param }),
+	"gt":       compareParam(func(size, param float64) bool { return size > param }),
+	"gte":      compareParam(func(size, param float64) bool { return size >= param }),
+	"lt":       compareParam(func(size, param float64) bool { return size < param }),
+	"lte":      compareParam(func(size, param float64) bool { return size <= param }),
+	"eq":       equalParam(true),
+	"ne":       equalParam(false),
+	"oneof":    isOneOf,
+	"email":    stringRule(isEmail),
+	"url":      stringRule(isURL),
+	"alpha":    stringRule(allRunes(unicode.IsLetter)),
+	"alphanum": stringRule(allRunes(func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) })),
+	"numeric":  stringRule(isNumeric),
+	"eqfield":  compareField(func(cmp int) bool { return cmp == 0 }),
+	"nefield":  compareField(func(cmp int) bool { return cmp != 0 }),
+	"gtfield":  compareField(func(cmp int) bool { return cmp > 0 }),
+	"gtefield": compareField(func(cmp int) bool { return cmp >= 0 }),
+	"ltfield":  compareField(func(cmp int) bool { return cmp < 0 }),
+	"ltefield": compareField(func(cmp int) bool { return cmp <= 0 }),
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// 获取用于比较的大小：字符串为字符数，容器为长度，数字为数值
+func sizeOf(rv reflect.Value) (float64, bool) {
+	switch rv.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(rv.String())), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(rv.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// 解析规则参数，time.Duration类型的字段支持1s、5m等写法
+func parseParam(rv reflect.Value, param string) (float64, bool) {
+	if rv.Type() == durationType {
+		if d, err := time.ParseDuration(param); err == nil {
+			return float64(d), true
+		}
+	}
+
+	p, err := strconv.ParseFloat(param, 64)
+	return p, err == nil
+}
+
+func compareParam(cmp func(size, param float64) bool) ValidationFunc {
+	return func(fl FieldLevel) bool {
+		field := indirectValue(fl.Field)
+		if !field.IsValid() {
+			return false
+		}
+
+		size, ok := sizeOf(field)
+		if !ok {
+			return false
+		}
+
+		param, ok := parseParam(field, fl.Param)
+		return ok && cmp(size, param)
+	}
+}
+
+func equalParam(want bool) ValidationFunc {
+	return func(fl FieldLevel) bool {
+		field := indirectValue(fl.Field)
+		if !field.IsValid() {
+			return !want
+		}
+
+		if field.Kind() == reflect.String {
+			return (field.String() == fl.Param) == want
+		}
+
+		size, ok := sizeOf(field)
+		if !ok {
+			return false
+		}
+
+		param, ok := parseParam(field, fl.Param)
+		return ok && (size == param) == want
+	}
+}
+
+// 值必须是以空格分隔的候选项之一
+func isOneOf(fl FieldLevel) bool {
+	field := indirectValue(fl.Field)
+
+	var value string
+	switch field.Kind() {
+	case reflect.String:
+		value = field.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = strconv.FormatInt(field.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = strconv.FormatUint(field.Uint(), 10)
+	default:
+		return false
+	}
+
+	for _, option := range strings.Fields(fl.Param) {
+		if option == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 仅作用于字符串的规则，空字符串视为通过，需要时配合required使用
+func stringRule(fn func(s string) bool) ValidationFunc {
+	return func(fl FieldLevel) bool {
+		field := indirectValue(fl.Field)
+		if !field.IsValid() || field.Kind() != reflect.String {
+			return false
+		}
+
+		return field.Len() == 0 || fn(field.String())
+	}
+}
+
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s && strings.Contains(s[strings.LastIndex(s, "@"):], ".")
+}
+
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && (u.Host != "" || u.Opaque != "")
+}
+
+func isNumeric(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+func allRunes(fn func(r rune) bool) func(s string) bool {
+	return func(s string) bool {
+		for _, r := range s {
+			if !fn(r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// 与同一结构体中的另一个字段比较，支持数字、字符串和time.Time
+func compareField(ok func(cmp int) bool) ValidationFunc {
+	return func(fl FieldLevel) bool {
+		if fl.Parent.Kind() != reflect.Struct {
+			return false
+		}
+
+		other := fl.Parent.FieldByName(fl.Param)
+		if !other.IsValid() {
+			panic("binding: cross-field rule refers to unknown field: " + fl.Param)
+		}
+
+		cmp, comparable := compareValues(indirectValue(fl.Field), indirectValue(other))
+		return comparable && ok(cmp)
+	}
+}
+
+// 比较两个值，返回-1、0、1
+func compareValues(a reflect.Value, b reflect.Value) (int, bool) {
+	if !a.IsValid() || !b.IsValid() {
+		return 0, false
+	}
+
+	if a.Type() == timeType && b.Type() == timeType {
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time)), true
+	}
+
+	if a.Kind() == reflect.String && b.Kind() == reflect.String {
+		return strings.Compare(a.String(), b.String()), true
+	}
+
+	x, okA := sizeOf(a)
+	y, okB := sizeOf(b)
+	if !okA || !okB {
+		if a.Type() == b.Type() && reflect.DeepEqual(a.Interface(), b.Interface()) {
+			return 0, true
+		}
+		return 0, false
+	}
+
+	switch {
+	case x < y:
+		return -1, true
+	case x > y:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
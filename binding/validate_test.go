@@ -0,0 +1,86 @@
+package binding
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type validateItem struct {
+	Name  string  `binding:"required"`
+	Price float64 `binding:"gt=0"`
+}
+
+type validateOrder struct {
+	User     string         `binding:"required,min=3,max=8,alphanum"`
+	Email    string         `binding:"omitempty,email"`
+	Status   string         `binding:"oneof=new paid"`
+	Start    time.Time      `binding:"required"`
+	End      time.Time      `binding:"gtfield=Start"`
+	Tags     []string       `binding:"max=3,dive,min=2"`
+	Items    []validateItem `binding:"required"`
+	Timeout  time.Duration  `binding:"lte=30s"`
+	Password string         `binding:"-"`
+}
+
+func TestTagValidator(t *testing.T) {
+	now := time.Now()
+	valid := validateOrder{
+		User:    "admin",
+		Status:  "new",
+		Start:   now,
+		End:     now.Add(time.Hour),
+		Tags:    []string{"go", "web"},
+		Items:   []validateItem{{Name: "dog", Price: 1}},
+		Timeout: 10 * time.Second,
+	}
+	if err := NewTagValidator().ValidateStruct(&valid); err != nil {
+		t.Fatalf("ValidateStruct(valid) = %v", err)
+	}
+
+	invalid := valid
+	invalid.User = "ad"
+	invalid.Email = "not-an-email"
+	invalid.Status = "closed"
+	invalid.End = now.Add(-time.Hour)
+	invalid.Tags = []string{"go", "x"}
+	invalid.Items = []validateItem{{Name: "dog", Price: 1}, {Price: -1}}
+	invalid.Timeout = time.Minute
+
+	err := NewTagValidator().ValidateStruct(&invalid)
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("ValidateStruct(invalid) = %v, want ValidationErrors", err)
+	}
+
+	got := make([]string, 0, len(errs))
+	for _, e := range errs {
+		got = append(got, e.Field+":"+e.Rule)
+	}
+	want := []string{
+		"User:min", "Email:email", "Status:oneof", "End:gtfield", "Tags[1]:min",
+		"Items[1].Name:required", "Items[1].Price:gt", "Timeout:lte",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("errors = %v, want %v", got, want)
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	v := NewTagValidator()
+	v.RegisterValidation("lower", func(fl FieldLevel) bool {
+		return strings.ToLower(fl.Field.String()) == fl.Field.String()
+	})
+
+	obj := struct {
+		Code string `binding:"lower"`
+	}{Code: "ABC"}
+
+	err := v.ValidateStruct(&obj)
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || errs[0].Rule != "lower" {
+		t.Errorf("ValidateStruct() = %v, want lower rule failure", err)
+	}
+}
@@ -1,27 +1,30 @@
 package binding
 
 import (
-	"bytes"
-	"encoding/xml"
-	"io"
+	"github.com/fangnan700/PoliteDog/codec"
 	"net/http"
 )
 
 type xmlBinding struct {
+	Codec codec.Codec // 为nil时使用codec.XML
 }
 
-func (j *xmlBinding) Name() string {
+func (x *xmlBinding) Name() string {
 	return "xml"
 }
 
-func (j *xmlBinding) Bind(r *http.Request, obj any) error {
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		return err
-	}
+func (x *xmlBinding) MediaType() string {
+	return MIMEXML
+}
+
+func (x *xmlBinding) WithCodec(c codec.Codec) CodecBinding {
+	return &xmlBinding{Codec: c}
+}
 
-	r.Body = io.NopCloser(bytes.NewReader(body))
-	decoder := xml.NewDecoder(bytes.NewReader(body))
+func (x *xmlBinding) Bind(r *http.Request, obj any) error {
+	return bindRequest(r, x, obj)
+}
 
-	return decoder.Decode(obj)
+func (x *xmlBinding) BindBody(body []byte, obj any) error {
+	return decodeBody(x.Codec, codec.XML, body, obj, false)
 }
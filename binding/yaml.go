@@ -0,0 +1,30 @@
+package binding
+
+import (
+	"github.com/fangnan700/PoliteDog/codec"
+	"net/http"
+)
+
+type yamlBinding struct {
+	Codec codec.Codec // 为nil时使用codec.YAML
+}
+
+func (y *yamlBinding) Name() string {
+	return "yaml"
+}
+
+func (y *yamlBinding) MediaType() string {
+	return MIMEYAML
+}
+
+func (y *yamlBinding) WithCodec(c codec.Codec) CodecBinding {
+	return &yamlBinding{Codec: c}
+}
+
+func (y *yamlBinding) Bind(r *http.Request, obj any) error {
+	return bindRequest(r, y, obj)
+}
+
+func (y *yamlBinding) BindBody(body []byte, obj any) error {
+	return decodeBody(y.Codec, codec.YAML, body, obj, false)
+}
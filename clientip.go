@@ -0,0 +1,107 @@
+package PoliteDog
+
+import (
+	"net"
+	"strings"
+)
+
+// 默认按顺序尝试的客户端IP请求头
+var defaultRemoteIPHeaders = []string{"Forwarded", "X-Forwarded-For", "X-Real-IP"}
+
+// 将IP或CIDR字符串解析为网段，单个IP视为/32或/128
+func parseTrustedProxy(proxy string) (*net.IPNet, error) {
+	proxy = strings.TrimSpace(proxy)
+	if strings.Contains(proxy, "/") {
+		_, ipNet, err := net.ParseCIDR(proxy)
+		return ipNet, err
+	}
+
+	ip := net.ParseIP(proxy)
+	if ip == nil {
+		return nil, &net.ParseError{Type: "IP address", Text: proxy}
+	}
+
+	bits := 32
+	if ip.To4() == nil {
+		bits = 128
+	} else {
+		ip = ip.To4()
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// 判断IP是否属于受信任的代理网段
+func (dog *Dog) isTrustedProxy(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, cidr := range dog.trustedCIDRs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 从指定请求头中解析客户端IP
+func (dog *Dog) clientIPFromHeader(header string, value string) (string, bool) {
+	var ips []string
+	switch strings.ToLower(header) {
+	case "forwarded":
+		ips = parseForwarded(value)
+	case "x-real-ip":
+		ips = []string{value}
+	default:
+		ips = strings.Split(value, ",")
+	}
+
+	// 从右往左跳过受信任的代理，第一个不受信任的地址即为客户端
+	for i := len(ips) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(ips[i]))
+		if ip == nil {
+			return "", false
+		}
+
+		if i == 0 || !dog.isTrustedProxy(ip) {
+			return ip.String(), true
+		}
+	}
+
+	return "", false
+}
+
+// 解析RFC 7239 Forwarded请求头，返回所有for参数中的IP
+func parseForwarded(value string) []string {
+	var ips []string
+	for _, element := range strings.Split(value, ",") {
+		for _, pair := range strings.Split(element, ";") {
+			key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || !strings.EqualFold(key, "for") {
+				continue
+			}
+
+			ips = append(ips, forwardedNodeIP(strings.Trim(val, `"`)))
+		}
+	}
+
+	return ips
+}
+
+// 去除Forwarded节点中的端口和IPv6方括号，unknown等混淆标识会被保留并在后续解析中判定为无效
+func forwardedNodeIP(node string) string {
+	if strings.HasPrefix(node, "[") {
+		if end := strings.Index(node, "]"); end > 0 {
+			return node[1:end]
+		}
+		return node
+	}
+
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		return host
+	}
+
+	return node
+}
@@ -0,0 +1,74 @@
+package codec
+
+import (
+	"io"
+	"mime"
+	"strings"
+	"sync"
+)
+
+// Encoder 流式编码器
+type Encoder interface {
+	Encode(v any) error
+}
+
+// Decoder 流式解码器
+type Decoder interface {
+	Decode(v any) error
+}
+
+// Codec 编解码器，binding和render通过它读写请求体和响应体
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
+
+// Registry 按媒体类型注册的编解码器，每个引擎持有各自的实例
+type Registry struct {
+	mu     sync.RWMutex
+	codecs map[string]Codec
+}
+
+// NewRegistry 创建注册了默认JSON、XML、YAML、TOML、MessagePack编解码器的Registry
+func NewRegistry() *Registry {
+	r := &Registry{codecs: make(map[string]Codec)}
+	r.Register("application/json", JSON)
+	r.Register("application/xml", XML)
+	r.Register("text/xml", XML)
+	r.Register("application/yaml", YAML)
+	r.Register("application/x-yaml", YAML)
+	r.Register("text/yaml", YAML)
+	r.Register("application/toml", TOML)
+	r.Register("application/msgpack", MsgPack)
+	r.Register("application/x-msgpack", MsgPack)
+
+	return r
+}
+
+// Register 注册编解码器，同一媒体类型会被覆盖
+func (r *Registry) Register(mediaType string, c Codec) {
+	r.mu.Lock()
+	r.codecs[normalize(mediaType)] = c
+	r.mu.Unlock()
+}
+
+// Lookup 获取媒体类型对应的编解码器，mediaType可以带有charset等参数
+func (r *Registry) Lookup(mediaType string) (Codec, bool) {
+	r.mu.RLock()
+	c, ok := r.codecs[normalize(mediaType)]
+	r.mu.RUnlock()
+
+	return c, ok
+}
+
+// 去除媒体类型中的参数并转为小写
+func normalize(mediaType string) string {
+	mt, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		mt, _, _ = strings.Cut(mediaType, ";")
+	}
+
+	return strings.ToLower(strings.TrimSpace(mt))
+}
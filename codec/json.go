@@ -0,0 +1,28 @@
+package codec
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSON 基于encoding/json的编解码器
+var JSON Codec = jsonCodec{}
+
+type jsonCodec struct {
+}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) NewEncoder(w io.Writer) Encoder {
+	return json.NewEncoder(w)
+}
+
+func (jsonCodec) NewDecoder(r io.Reader) Decoder {
+	return json.NewDecoder(r)
+}
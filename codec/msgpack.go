@@ -0,0 +1,431 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// MsgPack 内置的MessagePack编解码器，字段名使用msgpack标签，time.Time编码为时间戳扩展类型(-1)
+var MsgPack Codec = msgpackCodec{}
+
+type msgpackCodec struct {
+}
+
+func (msgpackCodec) Marshal(v any) ([]byte, error) {
+	val, err := toValue(reflect.ValueOf(v), "msgpack")
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeMsgPack(&buf, val)
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v any) error {
+	r := bytes.NewReader(data)
+	val, err := readMsgPack(r, 0)
+	if err != nil {
+		return err
+	}
+	if r.Len() > 0 {
+		return errors.New("codec: msgpack: unexpected data after top-level value")
+	}
+
+	return assign("msgpack", v, val, "msgpack")
+}
+
+func (msgpackCodec) NewEncoder(w io.Writer) Encoder {
+	return &msgpackEncoder{w: w}
+}
+
+func (msgpackCodec) NewDecoder(r io.Reader) Decoder {
+	return &msgpackDecoder{r: bufio.NewReader(r)}
+}
+
+type msgpackEncoder struct {
+	w io.Writer
+}
+
+func (e *msgpackEncoder) Encode(v any) error {
+	data, err := MsgPack.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(data)
+	return err
+}
+
+// 连续读取多个值，没有更多数据时返回io.EOF
+type msgpackDecoder struct {
+	r *bufio.Reader
+}
+
+func (d *msgpackDecoder) Decode(v any) error {
+	if _, err := d.r.Peek(1); err != nil {
+		return err
+	}
+
+	val, err := readMsgPack(d.r, 0)
+	if err != nil {
+		return err
+	}
+
+	return assign("msgpack", v, val, "msgpack")
+}
+
+/**
+编码
+*/
+
+func writeMsgPack(buf *bytes.Buffer, v any) {
+	switch val := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if val {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case int64:
+		writeMsgPackInt(buf, val)
+	case uint64:
+		writeMsgPackUint(buf, val)
+	case float64:
+		buf.WriteByte(0xcb)
+		buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(val)))
+	case string:
+		writeMsgPackHeader(buf, len(val), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		buf.WriteString(val)
+	case []byte:
+		writeMsgPackHeader(buf, len(val), 0, 0, 0xc4, 0xc5, 0xc6)
+		buf.Write(val)
+	case time.Time:
+		writeMsgPackTime(buf, val)
+	case []any:
+		writeMsgPackHeader(buf, len(val), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range val {
+			writeMsgPack(buf, item)
+		}
+	case mapValue:
+		writeMsgPackHeader(buf, len(val), 0x80, 16, 0, 0xde, 0xdf)
+		for _, e := range val {
+			writeMsgPack(buf, e.Key)
+			writeMsgPack(buf, e.Value)
+		}
+	default:
+		writeMsgPack(buf, fmt.Sprint(val))
+	}
+}
+
+func writeMsgPackInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0:
+		writeMsgPackUint(buf, uint64(i))
+	case i >= -32:
+		buf.WriteByte(byte(i))
+	case i >= math.MinInt8:
+		buf.Write([]byte{0xd0, byte(i)})
+	case i >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(i)))
+	case i >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(i)))
+	default:
+		buf.WriteByte(0xd3)
+		buf.Write(binary.BigEndian.AppendUint64(nil, uint64(i)))
+	}
+}
+
+func writeMsgPackUint(buf *bytes.Buffer, u uint64) {
+	switch {
+	case u < 128:
+		buf.WriteByte(byte(u))
+	case u <= math.MaxUint8:
+		buf.Write([]byte{0xcc, byte(u)})
+	case u <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(u)))
+	case u <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(u)))
+	default:
+		buf.WriteByte(0xcf)
+		buf.Write(binary.BigEndian.AppendUint64(nil, u))
+	}
+}
+
+// 写入长度头，fix为0时表示该类型没有fix格式，b8为0时表示没有8位长度格式
+func writeMsgPackHeader(buf *bytes.Buffer, n int, fix byte, fixMax int, b8, b16, b32 byte) {
+	switch {
+	case fix != 0 && n < fixMax:
+		buf.WriteByte(fix | byte(n))
+	case b8 != 0 && n <= math.MaxUint8:
+		buf.Write([]byte{b8, byte(n)})
+	case n <= math.MaxUint16:
+		buf.WriteByte(b16)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		buf.WriteByte(b32)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+// 按时间戳扩展类型的三种格式写入时间
+func writeMsgPackTime(buf *bytes.Buffer, t time.Time) {
+	sec, nsec := t.Unix(), uint64(t.Nanosecond())
+
+	switch {
+	case sec>>34 == 0 && nsec == 0 && sec <= math.MaxUint32:
+		buf.Write([]byte{0xd6, 0xff})
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(sec)))
+	case sec>>34 == 0:
+		buf.Write([]byte{0xd7, 0xff})
+		buf.Write(binary.BigEndian.AppendUint64(nil, nsec<<34|uint64(sec)))
+	default:
+		buf.Write([]byte{0xc7, 12, 0xff})
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(nsec)))
+		buf.Write(binary.BigEndian.AppendUint64(nil, uint64(sec)))
+	}
+}
+
+/**
+解码
+*/
+
+// 嵌套层数上限，防止恶意数据耗尽栈空间
+const msgpackMaxDepth = 10000
+
+type msgpackReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+func readMsgPack(r msgpackReader, depth int) (any, error) {
+	if depth > msgpackMaxDepth {
+		return nil, errors.New("codec: msgpack: exceeded max depth")
+	}
+
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, msgpackEOF(err)
+	}
+
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b >= 0x80 && b <= 0x8f:
+		return readMsgPackMap(r, int(b&0x0f), depth)
+	case b >= 0x90 && b <= 0x9f:
+		return readMsgPackArray(r, int(b&0x0f), depth)
+	case b >= 0xa0 && b <= 0xbf:
+		return readMsgPackString(r, int(b&0x1f))
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := readMsgPackLen(r, 1<<(b-0xc4))
+		if err != nil {
+			return nil, err
+		}
+		return readMsgPackBytes(r, n)
+	case 0xc7, 0xc8, 0xc9:
+		n, err := readMsgPackLen(r, 1<<(b-0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return readMsgPackExt(r, n)
+	case 0xca:
+		u, err := readMsgPackUint(r, 4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xcb:
+		u, err := readMsgPackUint(r, 8)
+		return math.Float64frombits(u), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := readMsgPackUint(r, 1<<(b-0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if u <= math.MaxInt64 {
+			return int64(u), nil
+		}
+		return u, nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (b - 0xd0)
+		u, err := readMsgPackUint(r, size)
+		if err != nil {
+			return nil, err
+		}
+		// 符号扩展
+		shift := 64 - 8*size
+		return int64(u<<shift) >> shift, nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return readMsgPackExt(r, 1<<(b-0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := readMsgPackLen(r, 1<<(b-0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return readMsgPackString(r, n)
+	case 0xdc, 0xdd:
+		n, err := readMsgPackLen(r, 2<<(b-0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return readMsgPackArray(r, n, depth)
+	case 0xde, 0xdf:
+		n, err := readMsgPackLen(r, 2<<(b-0xde))
+		if err != nil {
+			return nil, err
+		}
+		return readMsgPackMap(r, n, depth)
+	default:
+		return nil, fmt.Errorf("codec: msgpack: invalid type byte 0x%02x", b)
+	}
+}
+
+// 数据中途结束时返回io.ErrUnexpectedEOF
+func msgpackEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
+
+func readMsgPackUint(r msgpackReader, size int) (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(r, b[8-size:]); err != nil {
+		return 0, msgpackEOF(err)
+	}
+
+	return binary.BigEndian.Uint64(b[:]), nil
+}
+
+func readMsgPackLen(r msgpackReader, size int) (int, error) {
+	u, err := readMsgPackUint(r, size)
+	return int(u), err
+}
+
+// 按实际读取到的数据增长缓冲区，避免伪造的长度导致大量内存分配
+func readMsgPackBytes(r msgpackReader, n int) ([]byte, error) {
+	if n <= 64<<10 {
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, msgpackEOF(err)
+		}
+		return b, nil
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, int64(n)); err != nil {
+		return nil, msgpackEOF(err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func readMsgPackString(r msgpackReader, n int) (any, error) {
+	b, err := readMsgPackBytes(r, n)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func readMsgPackArray(r msgpackReader, n int, depth int) (any, error) {
+	items := make([]any, 0, min(n, 1024))
+	for i := 0; i < n; i++ {
+		item, err := readMsgPack(r, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// 读取映射，整数和布尔值的键转换为字符串
+func readMsgPackMap(r msgpackReader, n int, depth int) (any, error) {
+	m := make(map[string]any, min(n, 1024))
+	for i := 0; i < n; i++ {
+		k, err := readMsgPack(r, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		var key string
+		switch kv := k.(type) {
+		case string:
+			key = kv
+		case []byte:
+			key = string(kv)
+		case int64:
+			key = strconv.FormatInt(kv, 10)
+		case uint64:
+			key = strconv.FormatUint(kv, 10)
+		case bool:
+			key = strconv.FormatBool(kv)
+		default:
+			return nil, fmt.Errorf("codec: msgpack: unsupported map key type %T", k)
+		}
+
+		val, err := readMsgPack(r, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = val
+	}
+
+	return m, nil
+}
+
+// 读取扩展类型，目前只支持时间戳(-1)
+func readMsgPackExt(r msgpackReader, n int) (any, error) {
+	typ, err := r.ReadByte()
+	if err != nil {
+		return nil, msgpackEOF(err)
+	}
+	if int8(typ) != -1 {
+		return nil, fmt.Errorf("codec: msgpack: unsupported extension type %d", int8(typ))
+	}
+
+	switch n {
+	case 4:
+		sec, err := readMsgPackUint(r, 4)
+		return time.Unix(int64(sec), 0), err
+	case 8:
+		u, err := readMsgPackUint(r, 8)
+		return time.Unix(int64(u&(1<<34-1)), int64(u>>34)), err
+	case 12:
+		nsec, err := readMsgPackUint(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		sec, err := readMsgPackUint(r, 8)
+		return time.Unix(int64(sec), int64(nsec)), err
+	default:
+		return nil, fmt.Errorf("codec: msgpack: invalid timestamp length %d", n)
+	}
+}
@@ -0,0 +1,124 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type msgpackEvent struct {
+	ID      uint64            `msgpack:"id"`
+	Name    string            `msgpack:"name"`
+	Delta   int32             `msgpack:"delta"`
+	Score   float64           `msgpack:"score"`
+	OK      bool              `msgpack:"ok"`
+	Payload []byte            `msgpack:"payload"`
+	Tags    []string          `msgpack:"tags"`
+	Attrs   map[string]int    `msgpack:"attrs"`
+	At      time.Time         `msgpack:"at"`
+	Parent  *msgpackEvent     `msgpack:"parent,omitempty"`
+	Extra   map[string]string `msgpack:"-"`
+}
+
+func TestMsgPack_RoundTrip(t *testing.T) {
+	in := msgpackEvent{
+		ID: math.MaxUint64, Name: string(bytes.Repeat([]byte("x"), 300)), Delta: -70000, Score: 1.5, OK: true,
+		Payload: []byte{0, 1, 2},
+		Tags:    []string{"a", "b"},
+		Attrs:   map[string]int{"n": -1, "m": 200},
+		At:      time.Date(2024, 2, 17, 8, 0, 0, 123, time.UTC),
+		Parent:  &msgpackEvent{ID: 1, At: time.Unix(1<<35, 0)},
+	}
+
+	data, err := MsgPack.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out msgpackEvent
+	if err = MsgPack.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if !out.At.Equal(in.At) || !out.Parent.At.Equal(in.Parent.At) {
+		t.Errorf("At = %v / %v", out.At, out.Parent.At)
+	}
+	out.At, out.Parent.At = in.At, in.Parent.At
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestMsgPack_Format(t *testing.T) {
+	data, err := MsgPack.Marshal(map[string]any{"a": 1, "b": []any{true, nil, -1}, "t": time.Unix(1, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []byte{0x83, 0xa1, 'a', 0x01, 0xa1, 'b', 0x93, 0xc3, 0xc0, 0xff, 0xa1, 't', 0xd6, 0xff, 0, 0, 0, 1}
+	if !bytes.Equal(data, want) {
+		t.Errorf("got % x, want % x", data, want)
+	}
+}
+
+func TestMsgPack_Decoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := MsgPack.NewEncoder(&buf)
+	for i := 1; i <= 2; i++ {
+		if err := enc.Encode(msgpackEvent{ID: uint64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dec := MsgPack.NewDecoder(&buf)
+	var ids []uint64
+	for {
+		var e msgpackEvent
+		err := dec.Decode(&e)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, e.ID)
+	}
+	if !reflect.DeepEqual(ids, []uint64{1, 2}) {
+		t.Errorf("ids = %v", ids)
+	}
+
+	var e msgpackEvent
+	if err := MsgPack.Unmarshal([]byte{0x81, 0xa2, 'i', 'd', 0xa1, 'x'}, &e); err == nil {
+		t.Error("expected type error")
+	}
+	if err := MsgPack.Unmarshal([]byte{0xdb, 0xff, 0xff, 0xff, 0xff}, &e); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestMarshal_Cycle(t *testing.T) {
+	e := &msgpackEvent{Name: "loop"}
+	e.Parent = e
+	m := map[string]any{}
+	m["self"] = m
+	s := []any{nil}
+	s[0] = s
+
+	shared := &msgpackEvent{Name: "shared"}
+	if _, err := MsgPack.Marshal([]*msgpackEvent{shared, shared}); err != nil {
+		t.Errorf("shared pointer: %v", err)
+	}
+
+	for name, c := range map[string]Codec{"msgpack": MsgPack, "yaml": YAML, "toml": TOML} {
+		for _, v := range []any{e, map[string]any{"m": m}, map[string]any{"s": s}} {
+			if _, err := c.Marshal(v); err == nil || !strings.Contains(err.Error(), "encountered a cycle") {
+				t.Errorf("%s: err = %v", name, err)
+			}
+		}
+	}
+}
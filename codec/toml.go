@@ -0,0 +1,925 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TOML 内置的TOML编解码器，支持TOML 1.0的表、表数组、点分键、四种字符串、
+// 整数、浮点数、布尔值、日期时间、数组和内联表
+var TOML Codec = tomlCodec{}
+
+type tomlCodec struct {
+}
+
+func (tomlCodec) Marshal(v any) ([]byte, error) {
+	val, err := toValue(reflect.ValueOf(v), "toml")
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := val.(mapValue)
+	if !ok {
+		return nil, fmt.Errorf("codec: toml: top-level value must be a struct or map, got %T", v)
+	}
+
+	var buf bytes.Buffer
+	if err = writeTOMLTable(&buf, nil, m); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (tomlCodec) Unmarshal(data []byte, v any) error {
+	val, err := parseTOML(data)
+	if err != nil {
+		return err
+	}
+
+	return assign("toml", v, val, "toml")
+}
+
+func (tomlCodec) NewEncoder(w io.Writer) Encoder {
+	return &tomlEncoder{w: w}
+}
+
+func (tomlCodec) NewDecoder(r io.Reader) Decoder {
+	return &tomlDecoder{r: r}
+}
+
+type tomlEncoder struct {
+	w io.Writer
+}
+
+func (e *tomlEncoder) Encode(v any) error {
+	data, err := TOML.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(data)
+	return err
+}
+
+// TOML没有多文档，Decode读取全部内容，再次调用时返回io.EOF
+type tomlDecoder struct {
+	r    io.Reader
+	done bool
+}
+
+func (d *tomlDecoder) Decode(v any) error {
+	if d.done {
+		return io.EOF
+	}
+	d.done = true
+
+	data, err := io.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+
+	return TOML.Unmarshal(data, v)
+}
+
+/**
+解析
+*/
+
+// TOMLSyntaxError TOML语法错误
+type TOMLSyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *TOMLSyntaxError) Error() string {
+	return fmt.Sprintf("codec: toml: line %d: %s", e.Line, e.Msg)
+}
+
+// 数组和内联表的嵌套层数上限，防止恶意数据耗尽栈空间
+const tomlMaxDepth = 10000
+
+type tomlParser struct {
+	s       string
+	pos     int
+	line    int
+	depth   int
+	root    map[string]any
+	current map[string]any
+	defined map[string]bool // 已经通过[table]显式定义的表
+}
+
+func parseTOML(data []byte) (map[string]any, error) {
+	p := &tomlParser{
+		s:       strings.ReplaceAll(string(data), "\r\n", "\n"),
+		line:    1,
+		root:    make(map[string]any),
+		defined: make(map[string]bool),
+	}
+	p.current = p.root
+
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return p.root, nil
+		}
+
+		var err error
+		if p.peek() == '[' {
+			err = p.parseHeader()
+		} else {
+			err = p.parseKeyValue(p.current)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err = p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *tomlParser) errorf(format string, args ...any) error {
+	return &TOMLSyntaxError{Line: p.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+// 跳过空格和制表符
+func (p *tomlParser) skipSpace() {
+	for !p.eof() && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// 跳过空白、换行和注释
+func (p *tomlParser) skipBlank() {
+	for !p.eof() {
+		switch p.s[p.pos] {
+		case ' ', '\t':
+			p.pos++
+		case '\n':
+			p.pos++
+			p.line++
+		case '#':
+			p.skipComment()
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) skipComment() {
+	for !p.eof() && p.s[p.pos] != '\n' {
+		p.pos++
+	}
+}
+
+// 一行的内容结束后只允许空白和注释
+func (p *tomlParser) endOfLine() error {
+	p.skipSpace()
+	if p.peek() == '#' {
+		p.skipComment()
+	}
+	if p.eof() {
+		return nil
+	}
+	if p.s[p.pos] != '\n' {
+		return p.errorf("unexpected %q at end of line", p.s[p.pos])
+	}
+
+	p.pos++
+	p.line++
+	return nil
+}
+
+// 解析[table]或[[array]]
+func (p *tomlParser) parseHeader() error {
+	isArray := strings.HasPrefix(p.s[p.pos:], "[[")
+	if isArray {
+		p.pos += 2
+	} else {
+		p.pos++
+	}
+
+	p.skipSpace()
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+
+	p.skipSpace()
+	closing := "]"
+	if isArray {
+		closing = "]]"
+	}
+	if !strings.HasPrefix(p.s[p.pos:], closing) {
+		return p.errorf("expected %q to close table header", closing)
+	}
+	p.pos += len(closing)
+
+	parent := p.root
+	for _, key := range keys[:len(keys)-1] {
+		if parent, err = p.descend(parent, key); err != nil {
+			return err
+		}
+	}
+
+	last := keys[len(keys)-1]
+	name := strings.Join(keys, "\x00")
+	if isArray {
+		var arr []any
+		switch existing := parent[last].(type) {
+		case nil:
+		case []any:
+			arr = existing
+		default:
+			return p.errorf("key %q is already defined and is not an array of tables", strings.Join(keys, "."))
+		}
+
+		table := make(map[string]any)
+		parent[last] = append(arr, table)
+		p.current = table
+		return nil
+	}
+
+	switch existing := parent[last].(type) {
+	case nil:
+		table := make(map[string]any)
+		parent[last] = table
+		p.current = table
+	case map[string]any:
+		if p.defined[name] {
+			return p.errorf("table %q is already defined", strings.Join(keys, "."))
+		}
+		p.current = existing
+	default:
+		return p.errorf("key %q is already defined and is not a table", strings.Join(keys, "."))
+	}
+	p.defined[name] = true
+
+	return nil
+}
+
+// 进入子表，不存在时隐式创建，表数组进入最后一个元素
+func (p *tomlParser) descend(parent map[string]any, key string) (map[string]any, error) {
+	switch existing := parent[key].(type) {
+	case nil:
+		table := make(map[string]any)
+		parent[key] = table
+		return table, nil
+	case map[string]any:
+		return existing, nil
+	case []any:
+		if len(existing) > 0 {
+			if table, ok := existing[len(existing)-1].(map[string]any); ok {
+				return table, nil
+			}
+		}
+	}
+
+	return nil, p.errorf("key %q is already defined and is not a table", key)
+}
+
+// 解析key = value，点分键会创建中间的表
+func (p *tomlParser) parseKeyValue(table map[string]any) error {
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+
+	p.skipSpace()
+	if p.peek() != '=' {
+		return p.errorf("expected '=' after key %q", strings.Join(keys, "."))
+	}
+	p.pos++
+	p.skipSpace()
+
+	val, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys[:len(keys)-1] {
+		if table, err = p.descend(table, key); err != nil {
+			return err
+		}
+	}
+
+	last := keys[len(keys)-1]
+	if _, exists := table[last]; exists {
+		return p.errorf("duplicate key %q", strings.Join(keys, "."))
+	}
+	table[last] = val
+
+	return nil
+}
+
+// 解析可能带点的键
+func (p *tomlParser) parseKey() ([]string, error) {
+	var keys []string
+
+	for {
+		p.skipSpace()
+
+		var key string
+		switch c := p.peek(); {
+		case c == '"':
+			s, err := p.parseBasicString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		case c == '\'':
+			s, err := p.parseLiteralString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		default:
+			start := p.pos
+			for !p.eof() && isTOMLBareKeyChar(p.s[p.pos]) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, p.errorf("invalid key")
+			}
+			key = p.s[start:p.pos]
+		}
+		keys = append(keys, key)
+
+		p.skipSpace()
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+func isTOMLBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *tomlParser) parseValue() (any, error) {
+	if p.eof() {
+		return nil, p.errorf("expected a value")
+	}
+
+	if c := p.s[p.pos]; c == '[' || c == '{' {
+		p.depth++
+		defer func() { p.depth-- }()
+		if p.depth > tomlMaxDepth {
+			return nil, p.errorf("exceeded max depth")
+		}
+	}
+
+	switch c := p.s[p.pos]; {
+	case strings.HasPrefix(p.s[p.pos:], `"""`):
+		return p.parseMultilineBasicString()
+	case strings.HasPrefix(p.s[p.pos:], `'''`):
+		return p.parseMultilineLiteralString()
+	case c == '"':
+		return p.parseBasicString()
+	case c == '\'':
+		return p.parseLiteralString()
+	case c == '[':
+		return p.parseArray()
+	case c == '{':
+		return p.parseInlineTable()
+	case strings.HasPrefix(p.s[p.pos:], "true"):
+		p.pos += 4
+		return true, nil
+	case strings.HasPrefix(p.s[p.pos:], "false"):
+		p.pos += 5
+		return false, nil
+	default:
+		return p.parseNumberOrDate()
+	}
+}
+
+func (p *tomlParser) parseBasicString() (string, error) {
+	p.pos++
+	var sb strings.Builder
+
+	for !p.eof() {
+		c := p.s[p.pos]
+		switch c {
+		case '"':
+			p.pos++
+			return sb.String(), nil
+		case '\n':
+			return "", p.errorf("newline in basic string")
+		case '\\':
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	return "", p.errorf("unterminated string")
+}
+
+func (p *tomlParser) parseMultilineBasicString() (string, error) {
+	p.pos += 3
+	p.skipFirstNewline()
+	var sb strings.Builder
+
+	for !p.eof() {
+		if strings.HasPrefix(p.s[p.pos:], `"""`) {
+			p.pos += 3
+			// 结束符前最多可以紧跟两个引号
+			for i := 0; i < 2 && p.peek() == '"'; i++ {
+				sb.WriteByte('"')
+				p.pos++
+			}
+			return sb.String(), nil
+		}
+
+		c := p.s[p.pos]
+		switch c {
+		case '\\':
+			// 行尾的反斜杠会去除换行以及下一行开头的空白
+			rest := strings.TrimLeft(p.s[p.pos+1:], " \t")
+			if strings.HasPrefix(rest, "\n") {
+				p.pos = len(p.s) - len(rest)
+				for !p.eof() && strings.IndexByte(" \t\n", p.s[p.pos]) >= 0 {
+					if p.s[p.pos] == '\n' {
+						p.line++
+					}
+					p.pos++
+				}
+				continue
+			}
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+		case '\n':
+			p.line++
+			sb.WriteByte(c)
+			p.pos++
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	return "", p.errorf("unterminated multi-line string")
+}
+
+func (p *tomlParser) parseLiteralString() (string, error) {
+	p.pos++
+	end := strings.IndexAny(p.s[p.pos:], "'\n")
+	if end < 0 || p.s[p.pos+end] == '\n' {
+		return "", p.errorf("unterminated literal string")
+	}
+
+	s := p.s[p.pos : p.pos+end]
+	p.pos += end + 1
+	return s, nil
+}
+
+func (p *tomlParser) parseMultilineLiteralString() (string, error) {
+	p.pos += 3
+	p.skipFirstNewline()
+
+	end := strings.Index(p.s[p.pos:], "'''")
+	if end < 0 {
+		return "", p.errorf("unterminated multi-line literal string")
+	}
+	// 结束符前最多可以紧跟两个引号
+	for i := 0; i < 2 && p.pos+end+3 < len(p.s) && p.s[p.pos+end+3] == '\''; i++ {
+		end++
+	}
+
+	s := p.s[p.pos : p.pos+end]
+	p.line += strings.Count(s, "\n")
+	p.pos += end + 3
+	return s, nil
+}
+
+// 多行字符串开头紧跟的换行会被忽略
+func (p *tomlParser) skipFirstNewline() {
+	if p.peek() == '\n' {
+		p.pos++
+		p.line++
+	}
+}
+
+func (p *tomlParser) parseEscape(sb *strings.Builder) error {
+	if p.pos+1 >= len(p.s) {
+		return p.errorf("unterminated escape sequence")
+	}
+
+	c := p.s[p.pos+1]
+	p.pos += 2
+	switch c {
+	case 'b':
+		sb.WriteByte('\b')
+	case 't':
+		sb.WriteByte('\t')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'r':
+		sb.WriteByte('\r')
+	case 'e':
+		sb.WriteByte('\x1b')
+	case '"':
+		sb.WriteByte('"')
+	case '\\':
+		sb.WriteByte('\\')
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.s) {
+			return p.errorf("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(p.s[p.pos:p.pos+size], 16, 32)
+		if err != nil {
+			return p.errorf("invalid unicode escape \\%c%s", c, p.s[p.pos:p.pos+size])
+		}
+		sb.WriteRune(rune(code))
+		p.pos += size
+	default:
+		return p.errorf("invalid escape sequence \\%c", c)
+	}
+
+	return nil
+}
+
+// 解析数组，元素之间允许换行和注释，允许末尾的逗号
+func (p *tomlParser) parseArray() (any, error) {
+	p.pos++
+	items := make([]any, 0)
+
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.s[p.pos] == ']' {
+			p.pos++
+			return items, nil
+		}
+
+		item, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		p.skipBlank()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+func (p *tomlParser) parseInlineTable() (any, error) {
+	p.pos++
+	table := make(map[string]any)
+
+	p.skipSpace()
+	if p.peek() == '}' {
+		p.pos++
+		return table, nil
+	}
+
+	for {
+		p.skipSpace()
+		if err := p.parseKeyValue(table); err != nil {
+			return nil, err
+		}
+
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return table, nil
+		default:
+			return nil, p.errorf("expected ',' or '}' in inline table")
+		}
+	}
+}
+
+var (
+	tomlDatePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
+	tomlTimePattern = regexp.MustCompile(`^\d{2}:\d{2}(:\d{2}(\.\d+)?)?$`)
+	tomlLayouts     = []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02T15:04",
+		"2006-01-02",
+	}
+)
+
+// 解析数字、日期时间以及inf、nan
+func (p *tomlParser) parseNumberOrDate() (any, error) {
+	start := p.pos
+	for !p.eof() {
+		c := p.s[p.pos]
+		// 日期和时间之间可以用空格分隔
+		if c == ' ' && tomlDatePattern.MatchString(p.s[start:p.pos]) && p.pos-start == 10 &&
+			p.pos+1 < len(p.s) && p.s[p.pos+1] >= '0' && p.s[p.pos+1] <= '9' {
+			p.pos++
+			continue
+		}
+		if !isTOMLBareKeyChar(c) && strings.IndexByte("+.:", c) < 0 {
+			break
+		}
+		p.pos++
+	}
+
+	token := p.s[start:p.pos]
+	if token == "" {
+		return nil, p.errorf("expected a value")
+	}
+
+	switch strings.TrimLeft(token, "+-") {
+	case "inf":
+		if strings.HasPrefix(token, "-") {
+			return math.Inf(-1), nil
+		}
+		return math.Inf(1), nil
+	case "nan":
+		return math.NaN(), nil
+	}
+
+	if tomlDatePattern.MatchString(token) {
+		s := strings.Replace(token, " ", "T", 1)
+		for _, layout := range tomlLayouts {
+			if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+				return t, nil
+			}
+		}
+		return nil, p.errorf("invalid datetime %q", token)
+	}
+
+	// 本地时间没有对应的Go类型，保留为字符串
+	if tomlTimePattern.MatchString(token) {
+		return token, nil
+	}
+
+	if strings.Contains(token, "__") || strings.HasPrefix(token, "_") || strings.HasSuffix(token, "_") {
+		return nil, p.errorf("invalid number %q", token)
+	}
+	num := strings.ReplaceAll(token, "_", "")
+
+	if len(num) > 2 && num[0] == '0' && strings.IndexByte("xob", num[1]) >= 0 {
+		base := map[byte]int{'x': 16, 'o': 8, 'b': 2}[num[1]]
+		if u, err := strconv.ParseUint(num[2:], base, 64); err == nil {
+			if u <= math.MaxInt64 {
+				return int64(u), nil
+			}
+			return u, nil
+		}
+		return nil, p.errorf("invalid number %q", token)
+	}
+
+	if !strings.ContainsAny(num, ".eE") {
+		if i, err := strconv.ParseInt(num, 10, 64); err == nil {
+			return i, nil
+		}
+		if u, err := strconv.ParseUint(strings.TrimPrefix(num, "+"), 10, 64); err == nil {
+			return u, nil
+		}
+		return nil, p.errorf("invalid integer %q", token)
+	}
+
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return nil, p.errorf("invalid float %q", token)
+	}
+
+	return f, nil
+}
+
+/**
+编码
+*/
+
+var errTOMLNil = errors.New("codec: toml: nil values can only be omitted from tables")
+
+// 写入表的内容，先写键值对，再写子表和表数组
+func writeTOMLTable(buf *bytes.Buffer, path []string, m mapValue) error {
+	var tables, arrays []mapEntry
+
+	for _, e := range m {
+		switch val := e.Value.(type) {
+		case nil:
+			// TOML没有null，忽略空值
+			continue
+		case mapValue:
+			tables = append(tables, e)
+			continue
+		case []any:
+			if isTOMLArrayOfTables(val) {
+				arrays = append(arrays, e)
+				continue
+			}
+		}
+
+		buf.WriteString(tomlKey(e.Key))
+		buf.WriteString(" = ")
+		if err := writeTOMLValue(buf, e.Value); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+	}
+
+	for _, e := range tables {
+		sub := append(path[:len(path):len(path)], e.Key)
+		writeTOMLHeader(buf, "[", sub, "]")
+		if err := writeTOMLTable(buf, sub, e.Value.(mapValue)); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range arrays {
+		sub := append(path[:len(path):len(path)], e.Key)
+		for _, item := range e.Value.([]any) {
+			writeTOMLHeader(buf, "[[", sub, "]]")
+			if err := writeTOMLTable(buf, sub, item.(mapValue)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func writeTOMLHeader(buf *bytes.Buffer, open string, path []string, closing string) {
+	if buf.Len() > 0 {
+		buf.WriteByte('\n')
+	}
+
+	keys := make([]string, len(path))
+	for i, key := range path {
+		keys[i] = tomlKey(key)
+	}
+
+	buf.WriteString(open)
+	buf.WriteString(strings.Join(keys, "."))
+	buf.WriteString(closing)
+	buf.WriteByte('\n')
+}
+
+// 元素全部为表的非空数组写为[[array]]
+func isTOMLArrayOfTables(items []any) bool {
+	if len(items) == 0 {
+		return false
+	}
+
+	for _, item := range items {
+		if _, ok := item.(mapValue); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// 写入行内的值，表写为内联表
+func writeTOMLValue(buf *bytes.Buffer, v any) error {
+	switch val := v.(type) {
+	case nil:
+		return errTOMLNil
+	case bool:
+		buf.WriteString(strconv.FormatBool(val))
+	case int64:
+		buf.WriteString(strconv.FormatInt(val, 10))
+	case uint64:
+		buf.WriteString(strconv.FormatUint(val, 10))
+	case float64:
+		switch {
+		case math.IsInf(val, 1):
+			buf.WriteString("inf")
+		case math.IsInf(val, -1):
+			buf.WriteString("-inf")
+		case math.IsNaN(val):
+			buf.WriteString("nan")
+		default:
+			s := strconv.FormatFloat(val, 'g', -1, 64)
+			if !strings.ContainsAny(s, ".e") {
+				s += ".0"
+			}
+			buf.WriteString(s)
+		}
+	case time.Time:
+		buf.WriteString(val.Format(time.RFC3339Nano))
+	case []byte:
+		buf.WriteString(tomlString(base64Encode(val)))
+	case string:
+		buf.WriteString(tomlString(val))
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range val {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			if err := writeTOMLValue(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case mapValue:
+		buf.WriteByte('{')
+		first := true
+		for _, e := range val {
+			if e.Value == nil {
+				continue
+			}
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+
+			buf.WriteByte(' ')
+			buf.WriteString(tomlKey(e.Key))
+			buf.WriteString(" = ")
+			if err := writeTOMLValue(buf, e.Value); err != nil {
+				return err
+			}
+		}
+		if !first {
+			buf.WriteByte(' ')
+		}
+		buf.WriteByte('}')
+	default:
+		buf.WriteString(tomlString(fmt.Sprint(val)))
+	}
+
+	return nil
+}
+
+func tomlKey(key string) string {
+	if key == "" {
+		return `""`
+	}
+
+	for i := 0; i < len(key); i++ {
+		if !isTOMLBareKeyChar(key[i]) {
+			return tomlString(key)
+		}
+	}
+
+	return key
+}
+
+func tomlString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				sb.WriteString(fmt.Sprintf(`\u%04x`, r))
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+
+	return sb.String()
+}
@@ -0,0 +1,147 @@
+package codec
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type tomlConfig struct {
+	Title    string            `toml:"title"`
+	Owner    tomlOwner         `toml:"owner"`
+	Database tomlDatabase      `toml:"database"`
+	Servers  []tomlServer      `toml:"servers"`
+	Labels   map[string]string `toml:"labels"`
+}
+
+type tomlOwner struct {
+	Name string    `toml:"name"`
+	DOB  time.Time `toml:"dob"`
+}
+
+type tomlDatabase struct {
+	Ports   []int   `toml:"ports"`
+	MaxConn int64   `toml:"max_conn"`
+	Enabled bool    `toml:"enabled"`
+	Ratio   float64 `toml:"ratio"`
+}
+
+type tomlServer struct {
+	Host string `toml:"host"`
+	Role string `toml:"role"`
+}
+
+func TestTOML_Unmarshal(t *testing.T) {
+	data := `# config
+title = "TOML \"example\""
+labels = { env = "prod", "team name" = 'core' }
+
+[owner]
+name = 'Tom'
+dob = 1979-05-27T07:32:00-08:00
+
+[database]
+ports = [
+  8000,
+  8001, # comment
+]
+max_conn = 5_000
+enabled = true
+ratio = 6.5e-1
+
+[[servers]]
+host = """
+alpha"""
+role = "main"
+`
+
+	var c tomlConfig
+	if err := TOML.Unmarshal([]byte(data), &c); err != nil {
+		t.Fatal(err)
+	}
+
+	dob := time.Date(1979, 5, 27, 15, 32, 0, 0, time.UTC)
+	if c.Title != `TOML "example"` || c.Owner.Name != "Tom" || !c.Owner.DOB.Equal(dob) {
+		t.Errorf("unexpected title/owner: %+v", c)
+	}
+	if !reflect.DeepEqual(c.Database, tomlDatabase{Ports: []int{8000, 8001}, MaxConn: 5000, Enabled: true, Ratio: 0.65}) {
+		t.Errorf("database = %+v", c.Database)
+	}
+	if len(c.Servers) != 1 || c.Servers[0] != (tomlServer{Host: "alpha", Role: "main"}) {
+		t.Errorf("servers = %+v", c.Servers)
+	}
+	if c.Labels["team name"] != "core" {
+		t.Errorf("labels = %v", c.Labels)
+	}
+}
+
+func TestTOML_Values(t *testing.T) {
+	var m map[string]any
+	data := "hex = 0xff\noct = 0o17\nbin = 0b101\ninf = -inf\nlit = '''\na\\b'''\na.b.c = 1\n"
+	if err := TOML.Unmarshal([]byte(data), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	if m["hex"] != int64(255) || m["oct"] != int64(15) || m["bin"] != int64(5) || m["lit"] != `a\b` {
+		t.Errorf("unexpected values: %v", m)
+	}
+	if f, _ := m["inf"].(float64); !math.IsInf(f, -1) {
+		t.Errorf("inf = %v", m["inf"])
+	}
+	if m["a"].(map[string]any)["b"].(map[string]any)["c"] != int64(1) {
+		t.Errorf("dotted key = %v", m["a"])
+	}
+
+	var c tomlConfig
+	err := TOML.Unmarshal([]byte("[[servers]]\nhost = \"a\"\n[[servers]]\nrole.x = 1\n"), &c)
+	var ue *UnmarshalError
+	if !errors.As(err, &ue) || ue.Path != "servers[1].role" {
+		t.Errorf("err = %v", err)
+	}
+
+	for _, bad := range []string{"a = 1\na = 2", "[t]\n[t]", "a = ", "a = 1 b = 2"} {
+		if err := TOML.Unmarshal([]byte(bad), &m); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestTOML_RoundTrip(t *testing.T) {
+	in := tomlConfig{
+		Title:    "line\nbreak",
+		Owner:    tomlOwner{Name: "Tom", DOB: time.Date(2024, 2, 17, 8, 0, 0, 0, time.UTC)},
+		Database: tomlDatabase{Ports: []int{1, 2}, MaxConn: 10, Ratio: 3},
+		Servers:  []tomlServer{{Host: "a"}, {Host: "b", Role: "x"}},
+		Labels:   map[string]string{"a.b": "c"},
+	}
+
+	data, err := TOML.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out tomlConfig
+	if err = TOML.Unmarshal(data, &out); err != nil {
+		t.Fatalf("%v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(in, out) || !out.Owner.DOB.Equal(in.Owner.DOB) {
+		t.Errorf("got %+v, want %+v\n%s", out, in, data)
+	}
+}
+
+func TestTOML_MaxDepth(t *testing.T) {
+	n := 1 << 20
+	for name, data := range map[string]string{
+		"array":  "a = " + strings.Repeat("[", n) + strings.Repeat("]", n),
+		"inline": "a = " + strings.Repeat("{b=", n) + "1" + strings.Repeat("}", n),
+	} {
+		var v map[string]any
+		var se *TOMLSyntaxError
+		if err := TOML.Unmarshal([]byte(data), &v); !errors.As(err, &se) || !strings.Contains(se.Msg, "max depth") {
+			t.Errorf("%s: err = %v, want TOMLSyntaxError", name, err)
+		}
+	}
+}
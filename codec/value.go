@@ -0,0 +1,693 @@
+package codec
+
+import (
+	"encoding"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// 文本类格式（YAML、TOML）和MessagePack共用的反射层：
+// 编码时先将Go值转换为由nil、bool、int64、uint64、float64、string、[]byte、time.Time、
+// []any和mapValue组成的通用结构，解码时再将解析得到的通用结构赋值到目标对象
+
+// 保持键顺序的映射，结构体字段按定义顺序，map按键排序
+type mapValue []mapEntry
+
+type mapEntry struct {
+	Key   string
+	Value any
+}
+
+// UnmarshalError 解码后赋值失败，Path为出错字段的路径，如items[3].price
+type UnmarshalError struct {
+	Format string
+	Path   string
+	Value  any
+	Type   reflect.Type
+	Err    error
+}
+
+func (e *UnmarshalError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "value"
+	}
+
+	msg := fmt.Sprintf("codec: %s: cannot unmarshal %s into %s of type %s", e.Format, describe(e.Value), path, e.Type)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+
+	return msg
+}
+
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+// 描述原始值，用于错误信息
+func describe(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(val)
+	case []any:
+		return "array"
+	case map[string]any, mapValue:
+		return "object"
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// 结构体字段信息
+type fieldInfo struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+type fieldCacheKey struct {
+	t   reflect.Type
+	tag string
+}
+
+var fieldCache sync.Map // fieldCacheKey -> []fieldInfo
+
+// 获取结构体的字段信息，字段名依次取tag、json标签和字段名，未命名的内嵌结构体会被展开
+func structFields(t reflect.Type, tag string) []fieldInfo {
+	key := fieldCacheKey{t: t, tag: tag}
+	if cached, ok := fieldCache.Load(key); ok {
+		return cached.([]fieldInfo)
+	}
+
+	var fields []fieldInfo
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		name, opts := fieldTag(sf, tag)
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		if name == "" && sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			for _, inner := range structFields(sf.Type, tag) {
+				inner.index = append([]int{i}, inner.index...)
+				fields = append(fields, inner)
+			}
+			continue
+		}
+
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		fields = append(fields, fieldInfo{
+			name:      name,
+			index:     []int{i},
+			omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+		})
+	}
+
+	fieldCache.Store(key, fields)
+	return fields
+}
+
+// 读取字段标签，没有指定格式的标签时使用json标签
+func fieldTag(sf reflect.StructField, tag string) (string, string) {
+	value, ok := sf.Tag.Lookup(tag)
+	if !ok {
+		value = sf.Tag.Get("json")
+	}
+
+	name, opts, _ := strings.Cut(value, ",")
+	return name, opts
+}
+
+// 将Go值转换为通用结构
+func toValue(rv reflect.Value, tag string) (any, error) {
+	w := &valueWalker{tag: tag, seen: make(map[valueRef]struct{})}
+	return w.value(rv)
+}
+
+// 正在转换的指针、map和切片，用于检测循环引用
+type valueRef struct {
+	ptr unsafe.Pointer
+	typ reflect.Type
+	len int
+}
+
+type valueWalker struct {
+	tag  string
+	seen map[valueRef]struct{}
+}
+
+// 进入指针、map或切片前记录，返回的函数在离开时移除，重复进入时说明存在循环引用
+func (w *valueWalker) enter(rv reflect.Value) (func(), error) {
+	ref := valueRef{ptr: rv.UnsafePointer(), typ: rv.Type()}
+	if rv.Kind() == reflect.Slice {
+		ref.len = rv.Len()
+	}
+	if _, ok := w.seen[ref]; ok {
+		return nil, fmt.Errorf("codec: encountered a cycle via %s", rv.Type())
+	}
+
+	w.seen[ref] = struct{}{}
+	return func() { delete(w.seen, ref) }, nil
+}
+
+func (w *valueWalker) value(rv reflect.Value) (any, error) {
+	if !rv.IsValid() {
+		return nil, nil
+	}
+
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		if rv.Kind() == reflect.Pointer {
+			leave, err := w.enter(rv)
+			if err != nil {
+				return nil, err
+			}
+			defer leave()
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Type() {
+	case timeType:
+		return rv.Interface().(time.Time), nil
+	case durationType:
+		return time.Duration(rv.Int()).String(), nil
+	}
+
+	if marshaler, ok := textMarshaler(rv); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			if rv.Kind() == reflect.Slice && rv.IsNil() {
+				return nil, nil
+			}
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return b, nil
+		}
+		if rv.Kind() == reflect.Slice {
+			if rv.IsNil() {
+				return nil, nil
+			}
+			leave, err := w.enter(rv)
+			if err != nil {
+				return nil, err
+			}
+			defer leave()
+		}
+
+		items := make([]any, rv.Len())
+		for i := range items {
+			item, err := w.value(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		leave, err := w.enter(rv)
+		if err != nil {
+			return nil, err
+		}
+		defer leave()
+
+		m := make(mapValue, 0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key, err := mapKeyString(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			val, err := w.value(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, mapEntry{Key: key, Value: val})
+		}
+		sort.Slice(m, func(i, j int) bool { return m[i].Key < m[j].Key })
+		return m, nil
+	case reflect.Struct:
+		fields := structFields(rv.Type(), w.tag)
+		m := make(mapValue, 0, len(fields))
+		for _, f := range fields {
+			fv := rv.FieldByIndex(f.index)
+			if f.omitEmpty && isEmptyValue(fv) {
+				continue
+			}
+			val, err := w.value(fv)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, mapEntry{Key: f.name, Value: val})
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("codec: unsupported type %s", rv.Type())
+	}
+}
+
+// 获取值的TextMarshaler实现，兼容指针接收者
+func textMarshaler(rv reflect.Value) (encoding.TextMarshaler, bool) {
+	if rv.Type().Implements(textMarshalerType) {
+		return rv.Interface().(encoding.TextMarshaler), true
+	}
+	if rv.CanAddr() && rv.Addr().Type().Implements(textMarshalerType) {
+		return rv.Addr().Interface().(encoding.TextMarshaler), true
+	}
+
+	return nil, false
+}
+
+// 将map的键转为字符串
+func mapKeyString(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+	if key.Type().Implements(textMarshalerType) {
+		text, err := key.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	default:
+		return "", fmt.Errorf("codec: unsupported map key type %s", key.Type())
+	}
+}
+
+// 判断是否为omitempty需要省略的空值
+func isEmptyValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return rv.IsNil()
+	case reflect.Struct:
+		if rv.Type() == timeType {
+			return rv.Interface().(time.Time).IsZero()
+		}
+		return false
+	default:
+		return rv.IsZero()
+	}
+}
+
+// 将解析得到的通用结构赋值给目标对象，v必须为非nil指针
+func assign(format string, v any, src any, tag string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("codec: %s: Unmarshal(non-pointer %T)", format, v)
+	}
+
+	a := assigner{format: format, tag: tag}
+	return a.assign(rv.Elem(), src, "")
+}
+
+type assigner struct {
+	format string
+	tag    string
+}
+
+func (a assigner) typeError(dst reflect.Value, src any, path string, err error) error {
+	return &UnmarshalError{Format: a.format, Path: path, Value: src, Type: dst.Type(), Err: err}
+}
+
+func (a assigner) assign(dst reflect.Value, src any, path string) error {
+	if src == nil {
+		switch dst.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+			dst.Set(reflect.Zero(dst.Type()))
+		}
+		return nil
+	}
+
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return a.assign(dst.Elem(), src, path)
+	}
+
+	if dst.Kind() == reflect.Interface && dst.NumMethod() == 0 {
+		dst.Set(reflect.ValueOf(plain(src)))
+		return nil
+	}
+
+	switch dst.Type() {
+	case timeType:
+		t, err := toTime(src)
+		if err != nil {
+			return a.typeError(dst, src, path, err)
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		switch s := src.(type) {
+		case string:
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return a.typeError(dst, src, path, err)
+			}
+			dst.SetInt(int64(d))
+			return nil
+		}
+	}
+
+	if s, ok := src.(string); ok && dst.CanAddr() && dst.Addr().Type().Implements(textUnmarshalerType) {
+		if err := dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return a.typeError(dst, src, path, err)
+		}
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Bool:
+		b, ok := src.(bool)
+		if !ok {
+			return a.typeError(dst, src, path, nil)
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := toInt64(src)
+		if !ok || dst.OverflowInt(i) {
+			return a.typeError(dst, src, path, nil)
+		}
+		dst.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, ok := toUint64(src)
+		if !ok || dst.OverflowUint(u) {
+			return a.typeError(dst, src, path, nil)
+		}
+		dst.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, ok := toFloat64(src)
+		if !ok || dst.OverflowFloat(f) {
+			return a.typeError(dst, src, path, nil)
+		}
+		dst.SetFloat(f)
+	case reflect.String:
+		switch s := src.(type) {
+		case string:
+			dst.SetString(s)
+		case []byte:
+			dst.SetString(string(s))
+		case bool, int64, uint64, float64:
+			dst.SetString(fmt.Sprint(s))
+		default:
+			return a.typeError(dst, src, path, nil)
+		}
+	case reflect.Slice:
+		if dst.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := toBytes(src)
+			if err != nil {
+				return a.typeError(dst, src, path, err)
+			}
+			dst.SetBytes(b)
+			return nil
+		}
+
+		items, ok := src.([]any)
+		if !ok {
+			return a.typeError(dst, src, path, nil)
+		}
+		slice := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := a.assign(slice.Index(i), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+	case reflect.Array:
+		items, ok := src.([]any)
+		if !ok || len(items) > dst.Len() {
+			return a.typeError(dst, src, path, nil)
+		}
+		for i, item := range items {
+			if err := a.assign(dst.Index(i), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		return a.assignMap(dst, src, path)
+	case reflect.Struct:
+		return a.assignStruct(dst, src, path)
+	default:
+		return a.typeError(dst, src, path, nil)
+	}
+
+	return nil
+}
+
+func (a assigner) assignMap(dst reflect.Value, src any, path string) error {
+	m, ok := src.(map[string]any)
+	if !ok {
+		return a.typeError(dst, src, path, nil)
+	}
+
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMapWithSize(dst.Type(), len(m)))
+	}
+
+	keyType := dst.Type().Key()
+	for k, v := range m {
+		key := reflect.New(keyType).Elem()
+		if err := a.assignKey(key, k); err != nil {
+			return a.typeError(key, k, path, err)
+		}
+
+		val := reflect.New(dst.Type().Elem()).Elem()
+		if err := a.assign(val, v, joinPath(path, k)); err != nil {
+			return err
+		}
+		dst.SetMapIndex(key, val)
+	}
+
+	return nil
+}
+
+// 将字符串形式的键转换为map的键类型
+func (a assigner) assignKey(key reflect.Value, k string) error {
+	if reflect.PointerTo(key.Type()).Implements(textUnmarshalerType) {
+		return key.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(k))
+	}
+
+	switch key.Kind() {
+	case reflect.String:
+		key.SetString(k)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(k, 10, key.Type().Bits())
+		if err != nil {
+			return err
+		}
+		key.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(k, 10, key.Type().Bits())
+		if err != nil {
+			return err
+		}
+		key.SetUint(u)
+	default:
+		return fmt.Errorf("unsupported map key type %s", key.Type())
+	}
+
+	return nil
+}
+
+func (a assigner) assignStruct(dst reflect.Value, src any, path string) error {
+	m, ok := src.(map[string]any)
+	if !ok {
+		return a.typeError(dst, src, path, nil)
+	}
+
+	fields := structFields(dst.Type(), a.tag)
+	for k, v := range m {
+		f, ok := lookupField(fields, k)
+		if !ok {
+			continue
+		}
+
+		if err := a.assign(dst.FieldByIndex(f.index), v, joinPath(path, f.name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 查找字段，优先精确匹配，其次忽略大小写
+func lookupField(fields []fieldInfo, name string) (fieldInfo, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+
+	return fieldInfo{}, false
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// 将mapValue转换为map[string]any，用于赋值给interface{}
+func plain(v any) any {
+	switch val := v.(type) {
+	case mapValue:
+		m := make(map[string]any, len(val))
+		for _, e := range val {
+			m[e.Key] = plain(e.Value)
+		}
+		return m
+	case map[string]any:
+		for k, item := range val {
+			val[k] = plain(item)
+		}
+		return val
+	case []any:
+		for i, item := range val {
+			val[i] = plain(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+func toInt64(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case uint64:
+		return int64(n), n <= math.MaxInt64
+	case float64:
+		return int64(n), n == math.Trunc(n) && n >= math.MinInt64 && n <= math.MaxInt64
+	default:
+		return 0, false
+	}
+}
+
+func toUint64(v any) (uint64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return uint64(n), n >= 0
+	case uint64:
+		return n, true
+	case float64:
+		return uint64(n), n == math.Trunc(n) && n >= 0 && n <= math.MaxUint64
+	default:
+		return 0, false
+	}
+}
+
+func toFloat64(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+// 支持的时间格式
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+func toTime(v any) (time.Time, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case string:
+		var err error
+		for _, layout := range timeLayouts {
+			var parsed time.Time
+			if parsed, err = time.Parse(layout, t); err == nil {
+				return parsed, nil
+			}
+		}
+		return time.Time{}, err
+	default:
+		return time.Time{}, fmt.Errorf("not a time")
+	}
+}
+
+// 文本格式中的[]byte使用base64编码
+func toBytes(v any) ([]byte, error) {
+	switch b := v.(type) {
+	case []byte:
+		return b, nil
+	case string:
+		return base64.StdEncoding.DecodeString(b)
+	default:
+		return nil, fmt.Errorf("not bytes")
+	}
+}
+
+func base64Encode(b []byte) string {
+	return base64.StdEncoding.EncodeToString(b)
+}
@@ -0,0 +1,28 @@
+package codec
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// XML 基于encoding/xml的编解码器
+var XML Codec = xmlCodec{}
+
+type xmlCodec struct {
+}
+
+func (xmlCodec) Marshal(v any) ([]byte, error) {
+	return xml.Marshal(v)
+}
+
+func (xmlCodec) Unmarshal(data []byte, v any) error {
+	return xml.Unmarshal(data, v)
+}
+
+func (xmlCodec) NewEncoder(w io.Writer) Encoder {
+	return xml.NewEncoder(w)
+}
+
+func (xmlCodec) NewDecoder(r io.Reader) Decoder {
+	return xml.NewDecoder(r)
+}
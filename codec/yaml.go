@@ -0,0 +1,1008 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// YAML 内置的YAML编解码器，支持块映射、块序列、流式集合、标量和块字符串，
+// 不支持锚点、别名和标签
+var YAML Codec = yamlCodec{}
+
+type yamlCodec struct {
+}
+
+func (yamlCodec) Marshal(v any) ([]byte, error) {
+	val, err := toValue(reflect.ValueOf(v), "yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeYAMLBlock(&buf, val, 0)
+	return buf.Bytes(), nil
+}
+
+func (yamlCodec) Unmarshal(data []byte, v any) error {
+	val, err := parseYAML(data)
+	if err != nil {
+		return err
+	}
+
+	return assign("yaml", v, val, "yaml")
+}
+
+func (yamlCodec) NewEncoder(w io.Writer) Encoder {
+	return &yamlEncoder{w: w}
+}
+
+func (yamlCodec) NewDecoder(r io.Reader) Decoder {
+	return &yamlDecoder{r: r}
+}
+
+// 每次Encode写入一个文档，文档之间以---分隔
+type yamlEncoder struct {
+	w       io.Writer
+	started bool
+}
+
+func (e *yamlEncoder) Encode(v any) error {
+	data, err := YAML.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if e.started {
+		if _, err = io.WriteString(e.w, "---\n"); err != nil {
+			return err
+		}
+	}
+	e.started = true
+
+	_, err = e.w.Write(data)
+	return err
+}
+
+// 每次Decode读取一个以---分隔的文档，没有更多文档时返回io.EOF
+type yamlDecoder struct {
+	r    io.Reader
+	docs [][]byte
+	read bool
+}
+
+func (d *yamlDecoder) Decode(v any) error {
+	if !d.read {
+		data, err := io.ReadAll(d.r)
+		if err != nil {
+			return err
+		}
+		d.docs = splitYAMLDocuments(data)
+		d.read = true
+	}
+
+	if len(d.docs) == 0 {
+		return io.EOF
+	}
+
+	doc := d.docs[0]
+	d.docs = d.docs[1:]
+	return YAML.Unmarshal(doc, v)
+}
+
+// 按---拆分多文档
+func splitYAMLDocuments(data []byte) [][]byte {
+	var docs [][]byte
+	var current []string
+	hasContent := false
+
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimRight(line, " \t\r")
+		if trimmed == "---" || strings.HasPrefix(trimmed, "--- ") {
+			if hasContent {
+				docs = append(docs, []byte(strings.Join(current, "\n")))
+			}
+			current, hasContent = nil, false
+			continue
+		}
+		if trimmed == "..." {
+			continue
+		}
+
+		current = append(current, line)
+		if s := strings.TrimSpace(stripYAMLComment(line)); s != "" {
+			hasContent = true
+		}
+	}
+
+	if hasContent {
+		docs = append(docs, []byte(strings.Join(current, "\n")))
+	}
+
+	return docs
+}
+
+/**
+解析
+*/
+
+// YAMLSyntaxError YAML语法错误
+type YAMLSyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *YAMLSyntaxError) Error() string {
+	return fmt.Sprintf("codec: yaml: line %d: %s", e.Line, e.Msg)
+}
+
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+// 嵌套层数上限，防止恶意数据耗尽栈空间
+const yamlMaxDepth = 10000
+
+type yamlParser struct {
+	raw     []string
+	pos     int
+	virtual *yamlLine // 序列项"- "之后的内容，作为一行更深缩进的虚拟行处理
+	depth   int
+}
+
+// 解析单个YAML文档
+func parseYAML(data []byte) (any, error) {
+	docs := splitYAMLDocuments(data)
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	if len(docs) > 1 {
+		return nil, errors.New("codec: yaml: multiple documents, use a Decoder to read them one by one")
+	}
+
+	p := &yamlParser{raw: strings.Split(strings.ReplaceAll(string(docs[0]), "\r\n", "\n"), "\n")}
+	val, err := p.parseNode(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if line, ok, err := p.peek(); err != nil {
+		return nil, err
+	} else if ok {
+		return nil, p.errorf(line, "unexpected content %q", line.text)
+	}
+
+	return val, nil
+}
+
+func (p *yamlParser) errorf(line yamlLine, format string, args ...any) error {
+	return &YAMLSyntaxError{Line: line.num, Msg: fmt.Sprintf(format, args...)}
+}
+
+// 获取下一个有效行，跳过空行和注释
+func (p *yamlParser) peek() (yamlLine, bool, error) {
+	if p.virtual != nil {
+		return *p.virtual, true, nil
+	}
+
+	for p.pos < len(p.raw) {
+		raw := p.raw[p.pos]
+		text := strings.TrimRight(stripYAMLComment(raw), " \t\r")
+		content := strings.TrimLeft(text, " ")
+		if content == "" {
+			p.pos++
+			continue
+		}
+
+		line := yamlLine{num: p.pos + 1, indent: len(text) - len(content), text: content}
+		if content[0] == '\t' {
+			return line, false, p.errorf(line, "tabs are not allowed for indentation")
+		}
+
+		return line, true, nil
+	}
+
+	return yamlLine{}, false, nil
+}
+
+func (p *yamlParser) advance() {
+	if p.virtual != nil {
+		p.virtual = nil
+		return
+	}
+	p.pos++
+}
+
+// 解析缩进不小于minIndent的节点
+func (p *yamlParser) parseNode(minIndent int) (any, error) {
+	line, ok, err := p.peek()
+	if err != nil || !ok || line.indent < minIndent {
+		return nil, err
+	}
+
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > yamlMaxDepth {
+		return nil, p.errorf(line, "exceeded max depth")
+	}
+
+	if isYAMLSeqItem(line.text) {
+		return p.parseSeq(line.indent)
+	}
+
+	if _, _, isKey, err := p.splitKey(line); err != nil {
+		return nil, err
+	} else if isKey {
+		return p.parseMap(line.indent)
+	}
+
+	p.advance()
+	return p.parseInline(line, line.text, line.indent-1)
+}
+
+func (p *yamlParser) parseMap(indent int) (any, error) {
+	m := make(map[string]any)
+
+	for {
+		line, ok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if !ok || line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, p.errorf(line, "unexpected indentation")
+		}
+		if isYAMLSeqItem(line.text) {
+			return nil, p.errorf(line, "unexpected sequence item in mapping")
+		}
+
+		key, rest, isKey, err := p.splitKey(line)
+		if err != nil {
+			return nil, err
+		}
+		if !isKey {
+			return nil, p.errorf(line, "expected a mapping key, got %q", line.text)
+		}
+		if _, exists := m[key]; exists {
+			return nil, p.errorf(line, "duplicate key %q", key)
+		}
+		p.advance()
+
+		var val any
+		if rest == "" {
+			next, ok, err := p.peek()
+			if err != nil {
+				return nil, err
+			}
+			// 序列可以与键保持相同的缩进
+			if ok && (next.indent > indent || next.indent == indent && isYAMLSeqItem(next.text)) {
+				if val, err = p.parseNode(next.indent); err != nil {
+					return nil, err
+				}
+			}
+		} else if val, err = p.parseInline(line, rest, indent); err != nil {
+			return nil, err
+		}
+
+		m[key] = val
+	}
+
+	return m, nil
+}
+
+func (p *yamlParser) parseSeq(indent int) (any, error) {
+	items := make([]any, 0)
+
+	for {
+		line, ok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if !ok || line.indent != indent || !isYAMLSeqItem(line.text) {
+			if ok && line.indent > indent {
+				return nil, p.errorf(line, "unexpected indentation")
+			}
+			break
+		}
+
+		rest := strings.TrimLeft(line.text[1:], " ")
+		p.advance()
+
+		var val any
+		switch {
+		case rest == "":
+			next, ok, err := p.peek()
+			if err != nil {
+				return nil, err
+			}
+			if ok && next.indent > indent {
+				if val, err = p.parseNode(next.indent); err != nil {
+					return nil, err
+				}
+			}
+		case rest[0] == '|' || rest[0] == '>':
+			if val, err = p.parseInline(line, rest, indent); err != nil {
+				return nil, err
+			}
+		default:
+			// "- "之后的内容作为更深缩进的一行继续解析，以支持"- key: value"
+			contentIndent := indent + len(line.text) - len(rest)
+			p.virtual = &yamlLine{num: line.num, indent: contentIndent, text: rest}
+			if val, err = p.parseNode(contentIndent); err != nil {
+				return nil, err
+			}
+		}
+
+		items = append(items, val)
+	}
+
+	return items, nil
+}
+
+// 解析同一行内的值，parentIndent用于块字符串判断内容范围
+func (p *yamlParser) parseInline(line yamlLine, text string, parentIndent int) (any, error) {
+	switch text[0] {
+	case '|', '>':
+		return p.parseBlockScalar(line, text, parentIndent)
+	case '&', '*', '!':
+		return nil, p.errorf(line, "anchors, aliases and tags are not supported")
+	case '[', '{':
+		// 流式集合可以跨越多行
+		for !flowBalanced(text) {
+			next, ok, err := p.peek()
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, p.errorf(line, "unterminated flow collection")
+			}
+			text += " " + next.text
+			p.advance()
+		}
+
+		fp := &yamlFlowParser{s: text}
+		val, err := fp.parseValue()
+		if err != nil {
+			return nil, p.errorf(line, "%s", err.Error())
+		}
+		fp.skipSpace()
+		if fp.pos < len(fp.s) {
+			return nil, p.errorf(line, "unexpected content after flow collection: %q", fp.s[fp.pos:])
+		}
+		return val, nil
+	case '"', '\'':
+		s, n, err := parseYAMLQuoted(text)
+		if err != nil {
+			return nil, p.errorf(line, "%s", err.Error())
+		}
+		if strings.TrimSpace(text[n:]) != "" {
+			return nil, p.errorf(line, "unexpected content after quoted string: %q", text[n:])
+		}
+		return s, nil
+	default:
+		return resolveYAMLScalar(text), nil
+	}
+}
+
+// 解析块字符串，|保留换行，>折叠换行，-去除末尾换行，+保留全部末尾换行
+func (p *yamlParser) parseBlockScalar(line yamlLine, header string, parentIndent int) (any, error) {
+	style := header[0]
+	chomp := byte(0)
+	for _, c := range []byte(header[1:]) {
+		switch c {
+		case '-', '+':
+			chomp = c
+		case ' ':
+		default:
+			if c < '1' || c > '9' {
+				return nil, p.errorf(line, "invalid block scalar header %q", header)
+			}
+		}
+	}
+
+	var lines []string
+	blockIndent := -1
+	for p.pos < len(p.raw) {
+		raw := strings.TrimRight(p.raw[p.pos], "\r")
+		if strings.TrimSpace(raw) == "" {
+			lines = append(lines, "")
+			p.pos++
+			continue
+		}
+
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+		if indent <= parentIndent || blockIndent >= 0 && indent < blockIndent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = indent
+		}
+
+		lines = append(lines, raw[blockIndent:])
+		p.pos++
+	}
+
+	// 分离末尾的空行
+	end := len(lines)
+	for end > 0 && lines[end-1] == "" {
+		end--
+	}
+	trailing := len(lines) - end
+	lines = lines[:end]
+
+	var sb strings.Builder
+	for i, l := range lines {
+		if i > 0 {
+			if style == '>' && l != "" && lines[i-1] != "" && !strings.HasPrefix(l, " ") {
+				sb.WriteByte(' ')
+			} else {
+				sb.WriteByte('\n')
+			}
+		}
+		sb.WriteString(l)
+	}
+
+	switch {
+	case chomp == '-' || len(lines) == 0:
+	case chomp == '+':
+		sb.WriteString(strings.Repeat("\n", trailing+1))
+	default:
+		sb.WriteByte('\n')
+	}
+
+	return sb.String(), nil
+}
+
+// 拆分"key: value"，返回键、值和是否为映射项
+func (p *yamlParser) splitKey(line yamlLine) (string, string, bool, error) {
+	text := line.text
+
+	if text[0] == '"' || text[0] == '\'' {
+		key, n, err := parseYAMLQuoted(text)
+		if err != nil {
+			return "", "", false, nil
+		}
+		rest := strings.TrimLeft(text[n:], " ")
+		if !strings.HasPrefix(rest, ":") || len(rest) > 1 && rest[1] != ' ' {
+			return "", "", false, nil
+		}
+		return key, strings.TrimSpace(rest[1:]), true, nil
+	}
+
+	if text[0] == '[' || text[0] == '{' || text[0] == '?' {
+		return "", "", false, nil
+	}
+
+	idx := strings.Index(text, ": ")
+	if idx < 0 {
+		if !strings.HasSuffix(text, ":") {
+			return "", "", false, nil
+		}
+		idx = len(text) - 1
+	}
+
+	key := strings.TrimSpace(text[:idx])
+	if key == "" {
+		return "", "", false, p.errorf(line, "empty mapping key")
+	}
+
+	return key, strings.TrimSpace(text[idx+1:]), true, nil
+}
+
+func isYAMLSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// 去除注释，#位于行首或空白之后且不在引号内时视为注释
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"':
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				quote = 0
+			}
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			// 只有位于标量开头的引号才开启引用，避免it's这类文本被误判
+			if i == 0 || strings.IndexByte(" \t[{,:", line[i-1]) >= 0 {
+				quote = c
+			}
+		case c == '#':
+			if i == 0 || line[i-1] == ' ' || line[i-1] == '\t' {
+				return line[:i]
+			}
+		}
+	}
+
+	return line
+}
+
+// 判断流式集合的括号是否闭合
+func flowBalanced(s string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"':
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				quote = 0
+			}
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+
+	return depth <= 0
+}
+
+// 解析以引号开头的字符串，返回内容和消耗的字节数
+func parseYAMLQuoted(s string) (string, int, error) {
+	quote := s[0]
+	var sb strings.Builder
+
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if quote == '\'' {
+			if c == '\'' {
+				if i+1 < len(s) && s[i+1] == '\'' {
+					sb.WriteByte('\'')
+					i++
+					continue
+				}
+				return sb.String(), i + 1, nil
+			}
+			sb.WriteByte(c)
+			continue
+		}
+
+		switch c {
+		case '"':
+			return sb.String(), i + 1, nil
+		case '\\':
+			if i+1 >= len(s) {
+				return "", 0, errors.New("unterminated escape sequence")
+			}
+			r, n, err := unescapeYAML(s[i+1:])
+			if err != nil {
+				return "", 0, err
+			}
+			sb.WriteString(r)
+			i += n
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return "", 0, errors.New("unterminated quoted string")
+}
+
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v", 'f': "\f",
+	'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"", '/': "/", '\\': "\\", 'N': "\u0085",
+	'_': " ", 'L': " ", 'P': " ",
+}
+
+// 解析转义序列，返回结果和消耗的字节数
+func unescapeYAML(s string) (string, int, error) {
+	if r, ok := yamlEscapes[s[0]]; ok {
+		return r, 1, nil
+	}
+
+	var size int
+	switch s[0] {
+	case 'x':
+		size = 2
+	case 'u':
+		size = 4
+	case 'U':
+		size = 8
+	default:
+		return "", 0, fmt.Errorf("invalid escape sequence \\%c", s[0])
+	}
+
+	if len(s) < size+1 {
+		return "", 0, errors.New("invalid escape sequence")
+	}
+	code, err := strconv.ParseUint(s[1:size+1], 16, 32)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid escape sequence \\%s", s[:size+1])
+	}
+
+	return string(rune(code)), size + 1, nil
+}
+
+var (
+	yamlIntPattern   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlFloatPattern = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+
+	// YAML 1.1中的布尔值、数字（含下划线、二进制、六十进制）和时间戳，编码时需加引号以免被其他解析器误解析
+	yaml11BoolPattern      = regexp.MustCompile(`(?i)^(y|yes|n|no|on|off|true|false|null)$`)
+	yaml11NumberPattern    = regexp.MustCompile(`^[-+]?(0b[01_]+|0x[0-9a-fA-F_]+|[0-9][0-9_]*(:[0-5]?[0-9])*(\.[0-9_]*)?([eE][-+]?[0-9]+)?|\.[0-9_]+([eE][-+]?[0-9]+)?|\.(?i:inf|nan))$`)
+	yaml11TimestampPattern = regexp.MustCompile(`^[0-9]{4}-[0-9]{1,2}-[0-9]{1,2}([Tt \t].*)?$`)
+)
+
+// 按YAML 1.2核心模式解析普通标量
+func resolveYAMLScalar(s string) any {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1)
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1)
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN()
+	}
+
+	switch {
+	case yamlIntPattern.MatchString(s):
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(strings.TrimPrefix(s, "+"), 10, 64); err == nil {
+			return u
+		}
+	case strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0o"):
+		if i, err := strconv.ParseInt(s, 0, 64); err == nil {
+			return i
+		}
+	}
+
+	if yamlFloatPattern.MatchString(s) {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+
+	return s
+}
+
+// 流式集合解析器
+type yamlFlowParser struct {
+	s     string
+	pos   int
+	depth int
+}
+
+func (fp *yamlFlowParser) skipSpace() {
+	for fp.pos < len(fp.s) && (fp.s[fp.pos] == ' ' || fp.s[fp.pos] == '\t') {
+		fp.pos++
+	}
+}
+
+func (fp *yamlFlowParser) parseValue() (any, error) {
+	fp.skipSpace()
+	if fp.pos >= len(fp.s) {
+		return nil, errors.New("unexpected end of flow collection")
+	}
+
+	switch fp.s[fp.pos] {
+	case '[', '{':
+		fp.depth++
+		defer func() { fp.depth-- }()
+		if fp.depth > yamlMaxDepth {
+			return nil, errors.New("exceeded max depth")
+		}
+		if fp.s[fp.pos] == '[' {
+			return fp.parseSeq()
+		}
+		return fp.parseMap()
+	case '"', '\'':
+		s, n, err := parseYAMLQuoted(fp.s[fp.pos:])
+		fp.pos += n
+		return s, err
+	case '&', '*', '!':
+		return nil, errors.New("anchors, aliases and tags are not supported")
+	default:
+		return resolveYAMLScalar(fp.plain(false)), nil
+	}
+}
+
+// 读取普通标量，直到,]}或作为键时的": "
+func (fp *yamlFlowParser) plain(isKey bool) string {
+	start := fp.pos
+	for fp.pos < len(fp.s) {
+		c := fp.s[fp.pos]
+		if c == ',' || c == ']' || c == '}' {
+			break
+		}
+		if isKey && c == ':' && (fp.pos+1 == len(fp.s) || strings.IndexByte(" ,]}", fp.s[fp.pos+1]) >= 0) {
+			break
+		}
+		fp.pos++
+	}
+
+	return strings.TrimSpace(fp.s[start:fp.pos])
+}
+
+func (fp *yamlFlowParser) parseSeq() (any, error) {
+	fp.pos++
+	items := make([]any, 0)
+
+	for {
+		fp.skipSpace()
+		if fp.pos >= len(fp.s) {
+			return nil, errors.New("unterminated flow sequence")
+		}
+		if fp.s[fp.pos] == ']' {
+			fp.pos++
+			return items, nil
+		}
+
+		item, err := fp.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		if err = fp.separator(']'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (fp *yamlFlowParser) parseMap() (any, error) {
+	fp.pos++
+	m := make(map[string]any)
+
+	for {
+		fp.skipSpace()
+		if fp.pos >= len(fp.s) {
+			return nil, errors.New("unterminated flow mapping")
+		}
+		if fp.s[fp.pos] == '}' {
+			fp.pos++
+			return m, nil
+		}
+
+		var key string
+		if c := fp.s[fp.pos]; c == '"' || c == '\'' {
+			k, n, err := parseYAMLQuoted(fp.s[fp.pos:])
+			if err != nil {
+				return nil, err
+			}
+			key = k
+			fp.pos += n
+		} else {
+			key = fp.plain(true)
+		}
+
+		fp.skipSpace()
+		var val any
+		if fp.pos < len(fp.s) && fp.s[fp.pos] == ':' {
+			fp.pos++
+			fp.skipSpace()
+			if fp.pos < len(fp.s) && fp.s[fp.pos] != ',' && fp.s[fp.pos] != '}' {
+				v, err := fp.parseValue()
+				if err != nil {
+					return nil, err
+				}
+				val = v
+			}
+		}
+		m[key] = val
+
+		if err := fp.separator('}'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// 读取元素之间的逗号，遇到结束符时不消耗
+func (fp *yamlFlowParser) separator(end byte) error {
+	fp.skipSpace()
+	if fp.pos >= len(fp.s) {
+		return errors.New("unterminated flow collection")
+	}
+
+	switch fp.s[fp.pos] {
+	case ',':
+		fp.pos++
+		return nil
+	case end:
+		return nil
+	default:
+		return fmt.Errorf("unexpected character %q in flow collection", fp.s[fp.pos])
+	}
+}
+
+/**
+编码
+*/
+
+// 写入块结构的值
+func writeYAMLBlock(buf *bytes.Buffer, v any, indent int) {
+	switch val := v.(type) {
+	case mapValue:
+		if len(val) == 0 {
+			buf.WriteString(strings.Repeat(" ", indent) + "{}\n")
+			return
+		}
+		for _, e := range val {
+			buf.WriteString(strings.Repeat(" ", indent))
+			buf.WriteString(yamlString(e.Key))
+			buf.WriteByte(':')
+			writeYAMLChild(buf, e.Value, indent)
+		}
+	case []any:
+		if len(val) == 0 {
+			buf.WriteString(strings.Repeat(" ", indent) + "[]\n")
+			return
+		}
+		for _, item := range val {
+			buf.WriteString(strings.Repeat(" ", indent))
+			buf.WriteByte('-')
+			writeYAMLItem(buf, item, indent)
+		}
+	default:
+		buf.WriteString(strings.Repeat(" ", indent))
+		buf.WriteString(yamlScalar(val))
+		buf.WriteByte('\n')
+	}
+}
+
+// 写入"key:"之后的值
+func writeYAMLChild(buf *bytes.Buffer, v any, indent int) {
+	switch val := v.(type) {
+	case mapValue:
+		if len(val) == 0 {
+			buf.WriteString(" {}\n")
+			return
+		}
+		buf.WriteByte('\n')
+		writeYAMLBlock(buf, val, indent+2)
+	case []any:
+		if len(val) == 0 {
+			buf.WriteString(" []\n")
+			return
+		}
+		buf.WriteByte('\n')
+		writeYAMLBlock(buf, val, indent+2)
+	default:
+		buf.WriteByte(' ')
+		buf.WriteString(yamlScalar(val))
+		buf.WriteByte('\n')
+	}
+}
+
+// 写入"-"之后的值，集合的第一行与"-"写在同一行
+func writeYAMLItem(buf *bytes.Buffer, v any, indent int) {
+	switch val := v.(type) {
+	case mapValue, []any:
+		var child bytes.Buffer
+		writeYAMLBlock(&child, val, indent+2)
+		buf.WriteByte(' ')
+		buf.Write(child.Bytes()[indent+2:])
+	default:
+		buf.WriteByte(' ')
+		buf.WriteString(yamlScalar(val))
+		buf.WriteByte('\n')
+	}
+}
+
+func yamlScalar(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(val)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case uint64:
+		return strconv.FormatUint(val, 10)
+	case float64:
+		switch {
+		case math.IsInf(val, 1):
+			return ".inf"
+		case math.IsInf(val, -1):
+			return "-.inf"
+		case math.IsNaN(val):
+			return ".nan"
+		}
+		s := strconv.FormatFloat(val, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+		return s
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case []byte:
+		return yamlString(base64Encode(val))
+	case string:
+		return yamlString(val)
+	default:
+		return yamlString(fmt.Sprint(val))
+	}
+}
+
+// 字符串在会被误解析时使用双引号
+func yamlString(s string) string {
+	if !yamlNeedsQuote(s) {
+		return s
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				sb.WriteString(fmt.Sprintf(`\x%02x`, r))
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+
+	return sb.String()
+}
+
+func yamlNeedsQuote(s string) bool {
+	if s == "" || s != strings.TrimSpace(s) {
+		return true
+	}
+	if _, ok := resolveYAMLScalar(s).(string); !ok {
+		return true
+	}
+	if yaml11BoolPattern.MatchString(s) || yaml11NumberPattern.MatchString(s) || yaml11TimestampPattern.MatchString(s) {
+		return true
+	}
+	if strings.IndexByte("-?:,[]{}#&*!|>'\"%@`", s[0]) >= 0 {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return true
+	}
+
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return true
+		}
+	}
+
+	return false
+}
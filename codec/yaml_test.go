@@ -0,0 +1,164 @@
+package codec
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type yamlServer struct {
+	Name    string            `yaml:"name"`
+	Port    int               `yaml:"port"`
+	Debug   bool              `yaml:"debug"`
+	Ratio   float64           `yaml:"ratio"`
+	Timeout time.Duration     `yaml:"timeout"`
+	Tags    []string          `yaml:"tags"`
+	Labels  map[string]string `yaml:"labels"`
+	Routes  []yamlRoute       `yaml:"routes"`
+	Banner  string            `yaml:"banner"`
+	Note    string            `yaml:"note,omitempty"`
+}
+
+type yamlRoute struct {
+	Path    string   `yaml:"path"`
+	Methods []string `yaml:"methods"`
+}
+
+func TestYAML_Unmarshal(t *testing.T) {
+	data := `# server config
+name: "polite dog"
+port: 8080
+debug: true
+ratio: .5
+timeout: 1m30s
+tags: [a, 'b c', "d"]
+labels: {env: prod, team: core}
+routes:
+- path: /users # trailing comment
+  methods:
+    - GET
+    - POST
+- path: /health
+  methods: []
+banner: |
+  hello
+    world
+`
+
+	var s yamlServer
+	if err := YAML.Unmarshal([]byte(data), &s); err != nil {
+		t.Fatal(err)
+	}
+
+	want := yamlServer{
+		Name: "polite dog", Port: 8080, Debug: true, Ratio: 0.5, Timeout: 90 * time.Second,
+		Tags:   []string{"a", "b c", "d"},
+		Labels: map[string]string{"env": "prod", "team": "core"},
+		Routes: []yamlRoute{
+			{Path: "/users", Methods: []string{"GET", "POST"}},
+			{Path: "/health", Methods: []string{}},
+		},
+		Banner: "hello\n  world\n",
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("got %+v, want %+v", s, want)
+	}
+}
+
+func TestYAML_RoundTrip(t *testing.T) {
+	in := yamlServer{
+		Name: "true", Port: 1, Ratio: 2, Timeout: time.Second,
+		Tags:   []string{"- dash", "x: y"},
+		Labels: map[string]string{"b": "", "a": "line\nbreak"},
+		Routes: []yamlRoute{{Path: "/", Methods: []string{"GET"}}},
+		Banner: "#not a comment",
+	}
+
+	data, err := YAML.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out yamlServer
+	if err = YAML.Unmarshal(data, &out); err != nil {
+		t.Fatalf("%v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("got %+v, want %+v\n%s", out, in, data)
+	}
+}
+
+func TestYAML_MarshalQuote(t *testing.T) {
+	quoted := []string{"yes", "No", "NO", "y", "n", "on", "OFF", "True", "null", "1_000", "0b101", "0x_ff", "190:20:30", "1:30.5", "12e3", ".Inf", "2024-01-02", "2024-01-02 10:00:00"}
+	plain := []string{"yesterday", "name", "1.2.3", "v1_000", "2024-01", "10:00 am"}
+
+	for _, s := range quoted {
+		data, err := YAML.Marshal(map[string]string{"v": s})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "v: \"" + s + "\"\n"; string(data) != want {
+			t.Errorf("%q: got %q, want %q", s, data, want)
+		}
+
+		var out map[string]string
+		if err = YAML.Unmarshal(data, &out); err != nil || out["v"] != s {
+			t.Errorf("%q: round trip got %q, %v", s, out["v"], err)
+		}
+	}
+	for _, s := range plain {
+		if got := yamlString(s); got != s {
+			t.Errorf("%q was quoted as %s", s, got)
+		}
+	}
+}
+
+func TestYAML_Errors(t *testing.T) {
+	var s yamlServer
+	err := YAML.Unmarshal([]byte("routes:\n  - path: /\n    methods: 3\n"), &s)
+	var ue *UnmarshalError
+	if !errors.As(err, &ue) || ue.Path != "routes[0].methods" {
+		t.Errorf("err = %v", err)
+	}
+
+	for _, data := range []string{"a: &x 1", "a: 1\na: 2", "a:\n  b: 1\n   c: 2"} {
+		var m map[string]any
+		if err := YAML.Unmarshal([]byte(data), &m); err == nil {
+			t.Errorf("%q: expected error", data)
+		}
+	}
+}
+
+func TestYAML_Decoder(t *testing.T) {
+	d := YAML.NewDecoder(strings.NewReader("a: 1\n---\na: 2\n"))
+
+	var got []int
+	for {
+		var m struct{ A int }
+		if err := d.Decode(&m); err != nil {
+			break
+		}
+		got = append(got, m.A)
+	}
+
+	if !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestYAML_MaxDepth(t *testing.T) {
+	n := 1 << 20
+	for name, data := range map[string]string{
+		"flow":  "a: " + strings.Repeat("[", n) + strings.Repeat("]", n),
+		"map":   "a: " + strings.Repeat("{b: ", n) + "1" + strings.Repeat("}", n),
+		"block": strings.Repeat("- ", n) + "x",
+	} {
+		var v any
+		var se *YAMLSyntaxError
+		if err := YAML.Unmarshal([]byte(data), &v); !errors.As(err, &se) || !strings.Contains(se.Msg, "max depth") {
+			t.Errorf("%s: err = %v, want YAMLSyntaxError", name, err)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package PoliteDog
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 默认的最小压缩长度，小于该长度的响应压缩后收益很小
+const defaultCompressMinLength = 1024
+
+// DefaultUncompressedTypes 默认不压缩的响应类型，以/结尾的表示该大类下的所有类型
+var DefaultUncompressedTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp", "image/avif",
+	"video/", "audio/", "font/woff", "font/woff2",
+	"application/zip", "application/gzip", "application/x-gzip", "application/x-bzip2",
+	"application/x-xz", "application/x-7z-compressed", "application/x-rar-compressed", "application/zstd",
+}
+
+// CompressOptions 响应压缩配置
+type CompressOptions struct {
+	Level             int      // 压缩级别，为0时使用默认级别
+	MinLength         int      // 最小压缩长度，为0时为1024字节
+	UncompressedTypes []string // 不压缩的响应类型，为nil时使用DefaultUncompressedTypes
+}
+
+// 可复用的压缩器
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// Compress 响应压缩中间件，根据Accept-Encoding的q值选择gzip或deflate，
+// 跳过过短的响应、已压缩的类型和部分内容响应，流式响应调用Flush时立即压缩输出
+func Compress(opts CompressOptions) HandlerFuc {
+	if opts.Level == 0 {
+		opts.Level = flate.DefaultCompression
+	}
+	if opts.MinLength == 0 {
+		opts.MinLength = defaultCompressMinLength
+	}
+	if opts.UncompressedTypes == nil {
+		opts.UncompressedTypes = DefaultUncompressedTypes
+	}
+	if _, err := flate.NewWriter(io.Discard, opts.Level); err != nil {
+		panic(err)
+	}
+
+	pools := map[string]*sync.Pool{
+		"gzip": {New: func() any {
+			w, _ := gzip.NewWriterLevel(io.Discard, opts.Level)
+			return w
+		}},
+		"deflate": {New: func() any {
+			w, _ := flate.NewWriter(io.Discard, opts.Level)
+			return w
+		}},
+	}
+
+	return func(ctx *Context) {
+		ctx.w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(ctx.r.Header.Get("Accept-Encoding"))
+		if encoding == "" || ctx.r.Header.Get("Upgrade") != "" {
+			ctx.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: ctx.w, opts: &opts, encoding: encoding, pool: pools[encoding]}
+		ctx.w = w
+		defer func() {
+			ctx.w = w.ResponseWriter
+			_ = w.Close()
+		}()
+
+		ctx.Next()
+	}
+}
+
+// 按q值选择压缩方式，q值相同时优先gzip，都不接受时返回空字符串
+func negotiateEncoding(acceptEncoding string) string {
+	best, bestQ := "", 0.0
+	wildcard := -1.0
+	q := map[string]float64{}
+
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		weight := 1.0
+		if key, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(key) == "q" {
+			f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			weight = f
+		}
+
+		if name == "*" {
+			wildcard = weight
+		} else {
+			q[name] = weight
+		}
+	}
+
+	for _, name := range []string{"gzip", "deflate"} {
+		weight, ok := q[name]
+		if !ok {
+			weight = max(wildcard, 0)
+		}
+		if weight > bestQ {
+			best, bestQ = name, weight
+		}
+	}
+
+	return best
+}
+
+// 压缩响应的写入器，数据达到最小压缩长度或被刷新前先缓存，以便根据长度和类型决定是否压缩
+type compressWriter struct {
+	http.ResponseWriter
+	opts     *CompressOptions
+	encoding string
+	pool     *sync.Pool
+
+	status  int
+	buf     []byte
+	decided bool
+	comp    compressor
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status != 0 {
+		return
+	}
+
+	w.status = code
+	// 没有响应体的状态码直接写出
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+		_ = w.decide(false)
+	}
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		return w.write(data)
+	}
+
+	w.buf = append(w.buf, data...)
+	if len(w.buf) < w.opts.MinLength {
+		return len(data), nil
+	}
+	if err := w.decide(true); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+func (w *compressWriter) write(data []byte) (int, error) {
+	if w.comp != nil {
+		return w.comp.Write(data)
+	}
+
+	return w.ResponseWriter.Write(data)
+}
+
+// 决定是否压缩并写出响应头，allow为false时不压缩
+func (w *compressWriter) decide(allow bool) error {
+	w.decided = true
+	header := w.ResponseWriter.Header()
+
+	// 与net/http相同，根据原始内容推断类型，避免对压缩后的数据推断
+	if header.Get("Content-Type") == "" && len(w.buf) > 0 {
+		header.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+
+	if allow && w.compressible(header) {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		// 压缩后的内容与原内容不同，强ETag需要改为弱ETag
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+
+		w.comp = w.pool.Get().(compressor)
+		w.comp.Reset(w.ResponseWriter)
+	}
+
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+
+	if len(w.buf) > 0 {
+		buf := w.buf
+		w.buf = nil
+		_, err := w.write(buf)
+		return err
+	}
+	return nil
+}
+
+// 已设置编码、部分内容响应和不压缩的类型返回false
+func (w *compressWriter) compressible(header http.Header) bool {
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" || w.status == http.StatusPartialContent {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+
+	for _, t := range w.opts.UncompressedTypes {
+		if mediaType == t || strings.HasSuffix(t, "/") && strings.HasPrefix(mediaType, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// Flush 压缩并写出已缓存的数据，用于流式响应和SSE
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(true)
+	}
+	if w.comp != nil {
+		_ = w.comp.Flush()
+	}
+
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap 供http.ResponseController获取原始的写入器
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Close 写出剩余数据并归还压缩器，未达到最小压缩长度的响应不压缩
+func (w *compressWriter) Close() error {
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			return err
+		}
+	}
+	if w.comp == nil {
+		return nil
+	}
+
+	err := w.comp.Close()
+	w.comp.Reset(io.Discard)
+	w.pool.Put(w.comp)
+	w.comp = nil
+
+	return err
+}
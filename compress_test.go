@@ -0,0 +1,142 @@
+package PoliteDog
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	cases := map[string]string{
+		"":                          "",
+		"gzip, deflate, br":         "gzip",
+		"deflate":                   "deflate",
+		"gzip;q=0.5, deflate;q=0.8": "deflate",
+		"gzip;q=0, *":               "deflate",
+		"*;q=0.1":                   "gzip",
+		"br, identity":              "",
+		"GZIP;q=1.0":                "gzip",
+	}
+
+	for header, want := range cases {
+		if got := negotiateEncoding(header); got != want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat("PoliteDog ", 500)
+	file := filepath.Join(t.TempDir(), "large.txt")
+	if err := os.WriteFile(file, []byte(large), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	router := NewRouter()
+	router.GET("/large", func(ctx *Context) {
+		_ = ctx.String(http.StatusOK, large)
+	})
+	router.GET("/small", func(ctx *Context) {
+		_ = ctx.String(http.StatusOK, "ok")
+	})
+	router.GET("/image", func(ctx *Context) {
+		ctx.SetHeader("Content-Type", "image/png")
+		_ = ctx.Data(http.StatusOK, []byte(large))
+	})
+	router.GET("/file", func(ctx *Context) {
+		ctx.File(http.StatusOK, file)
+	})
+	router.GET("/content", func(ctx *Context) {
+		ctx.ServeContent("large.txt", time.Time{}, strings.NewReader(large))
+	})
+	router.GET("/events", func(ctx *Context) {
+		ctx.SetHeader("Content-Type", "text/event-stream")
+		ctx.Status(http.StatusOK)
+		w := ctx.Writer()
+		_, _ = io.WriteString(w, "data: 1\n\n")
+		_ = http.NewResponseController(w).Flush()
+		_, _ = io.WriteString(w, "data: 2\n\n")
+	})
+
+	dog := NewDog()
+	dog.Use(Compress(CompressOptions{}))
+	dog.RegisterRouters(router)
+
+	serve := func(path string, acceptEncoding string, header map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		dog.ServeHTTP(w, r)
+		return w
+	}
+
+	decode := func(t *testing.T, w *httptest.ResponseRecorder) string {
+		t.Helper()
+		var r io.Reader = w.Body
+		switch w.Header().Get("Content-Encoding") {
+		case "gzip":
+			gr, err := gzip.NewReader(w.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r = gr
+		case "deflate":
+			r = flate.NewReader(w.Body)
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	cases := []struct {
+		path, acceptEncoding string
+		header               map[string]string
+		encoding, body       string
+		code                 int
+	}{
+		{"/large", "gzip, deflate", nil, "gzip", large, http.StatusOK},
+		{"/large", "gzip;q=0.2, deflate", nil, "deflate", large, http.StatusOK},
+		{"/large", "", nil, "", large, http.StatusOK},
+		{"/small", "gzip", nil, "", "ok", http.StatusOK},
+		{"/image", "gzip", nil, "", large, http.StatusOK},
+		{"/file", "gzip", nil, "gzip", large, http.StatusOK},
+		{"/content", "gzip", nil, "gzip", large, http.StatusOK},
+		{"/content", "gzip", map[string]string{"Range": "bytes=0-8"}, "", large[:9], http.StatusPartialContent},
+		{"/events", "gzip", nil, "gzip", "data: 1\n\ndata: 2\n\n", http.StatusOK},
+	}
+	for _, tc := range cases {
+		w := serve(tc.path, tc.acceptEncoding, tc.header)
+		if w.Code != tc.code {
+			t.Errorf("%s %q: code = %d, want %d", tc.path, tc.acceptEncoding, w.Code, tc.code)
+		}
+		if got := w.Header().Get("Content-Encoding"); got != tc.encoding {
+			t.Errorf("%s %q: Content-Encoding = %q, want %q", tc.path, tc.acceptEncoding, got, tc.encoding)
+		}
+		if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("%s %q: Vary = %q", tc.path, tc.acceptEncoding, got)
+		}
+		if tc.encoding != "" && w.Header().Get("Content-Length") != "" {
+			t.Errorf("%s %q: Content-Length was not removed", tc.path, tc.acceptEncoding)
+		}
+		if got := decode(t, w); got != tc.body {
+			t.Errorf("%s %q: body = %.40q, want %.40q", tc.path, tc.acceptEncoding, got, tc.body)
+		}
+	}
+
+	// 流式响应在Flush时写出已压缩的数据
+	if w := serve("/events", "gzip", nil); !w.Flushed {
+		t.Error("event stream was not flushed")
+	}
+}
@@ -1,20 +1,37 @@
 package PoliteDog
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
 	"errors"
 	"github.com/fangnan700/PoliteDog/binding"
 	"github.com/fangnan700/PoliteDog/render"
-	"html/template"
 	"io"
+	"maps"
+	"math"
+	"mime"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-const defaultMultipartMaxMemory = 32
+// 解析multipart表单时默认使用的最大内存
+const defaultMultipartMemory = 32 << 20
+
+// BodyBytes默认允许缓存的最大字节数
+const defaultMaxBodyCacheBytes = 32 << 20
+
+// 处理链被中止后的索引，足够大以保证后续handler不再执行
+const abortIndex = math.MaxInt >> 1
 
 // Context 上下文封装
 type Context struct {
@@ -24,6 +41,9 @@ type Context struct {
 	r *http.Request
 	m sync.RWMutex
 
+	// 是否已随请求结束被释放（仅调试模式下设置）
+	released atomic.Bool
+
 	// 主体函数和中间件列表
 	index    int
 	handlers []HandlerFuc
@@ -31,21 +51,106 @@ type Context struct {
 	// 请求数据
 	Method     string
 	Path       string
+	Params     Params
+	fullPath   string
 	queryCache url.Values
 	formCache  url.Values
 
+	// 当前路由生效的multipart内存限制
+	maxMultipartMemory int64
+
+	// 请求体缓存
+	bodyCache  []byte
+	bodyCached bool
+
 	// 响应数据
-	Code int
+	Code   int
+	Errors []error // 处理过程中通过AbortWithError记录的错误
 
 	// 其它参数
 	Keys                  map[string]any
-	DisallowUnknownFields bool // 是否校验json对应结构体字段
+	typedKeys             map[any]any // 通过Set/Get设置的类型化密钥
+	DisallowUnknownFields bool        // 是否校验json对应结构体字段
+}
+
+// 为新请求重置上下文
+func (c *Context) reset(w http.ResponseWriter, r *http.Request) {
+	c.w = w
+	c.r = r
+	c.released.Store(false)
+	c.index = -1
+	c.handlers = make([]HandlerFuc, 0)
+	c.Method = r.Method
+	c.Path = r.URL.Path
+	c.Params = c.Params[:0]
+	c.fullPath = ""
+	c.queryCache = nil
+	c.formCache = nil
+	c.bodyCache = nil
+	c.bodyCached = false
+	c.maxMultipartMemory = c.e.MaxMultipartMemory
+	c.Code = 0
+	c.Errors = nil
+}
+
+// 检查上下文是否在请求结束后被继续使用
+func (c *Context) checkReleased() {
+	if c.released.Load() {
+		panic("PoliteDog: Context used after the request finished, use ctx.Copy() before passing it to a goroutine")
+	}
+}
+
+// Copy 复制一份与当前请求分离的只读上下文，可以安全地在后台协程中使用，
+// 副本中的响应写入不会生效
+func (c *Context) Copy() *Context {
+	c.checkReleased()
+
+	r := c.r.Clone(context.WithoutCancel(c.r.Context()))
+	r.Body = http.NoBody
+
+	c.m.RLock()
+	keys := maps.Clone(c.Keys)
+	typedKeys := maps.Clone(c.typedKeys)
+	c.m.RUnlock()
+
+	return &Context{
+		e:                     c.e,
+		w:                     readOnlyWriter{header: make(http.Header)},
+		r:                     r,
+		index:                 abortIndex,
+		Method:                c.Method,
+		Path:                  c.Path,
+		Params:                slices.Clone(c.Params),
+		fullPath:              c.fullPath,
+		queryCache:            maps.Clone(c.queryCache),
+		formCache:             maps.Clone(c.formCache),
+		maxMultipartMemory:    c.maxMultipartMemory,
+		bodyCache:             c.bodyCache,
+		bodyCached:            c.bodyCached,
+		Code:                  c.Code,
+		Keys:                  keys,
+		typedKeys:             typedKeys,
+		DisallowUnknownFields: c.DisallowUnknownFields,
+	}
+}
+
+// FullPath 返回匹配到的路由模式，如/user/info/:id，未匹配时返回空字符串
+func (c *Context) FullPath() string {
+	c.checkReleased()
+	return c.fullPath
 }
 
 /**
 参数解析
 */
 
+// Param 获取路由参数，如/user/:id中的id
+func (c *Context) Param(key string) string {
+	c.checkReleased()
+	val, _ := c.Params.Get(key)
+	return val
+}
+
 // 初始化queryCache
 func (c *Context) initQueryCache() {
 	if c.r != nil {
@@ -57,26 +162,74 @@ func (c *Context) initQueryCache() {
 
 // GetQuery 获取query参数
 func (c *Context) GetQuery(key string) any {
+	c.checkReleased()
 	c.initQueryCache()
 	return c.queryCache.Get(key)
 }
 
 // GetQueryArray 获取query参数切片
 func (c *Context) GetQueryArray(key string) ([]string, bool) {
+	c.checkReleased()
 	c.initQueryCache()
 	val, ok := c.queryCache[key]
 	return val, ok
 }
 
+// 限制请求体大小，Content-Length已超出时直接响应413并返回false
+func (c *Context) limitBody(maxBytes int64) bool {
+	if maxBytes <= 0 {
+		return true
+	}
+
+	if c.r.ContentLength > maxBytes {
+		c.AbortWithStatus(http.StatusRequestEntityTooLarge)
+		return false
+	}
+
+	c.r.Body = http.MaxBytesReader(c.w, c.r.Body, maxBytes)
+	return true
+}
+
+// 读取请求体出错时，若为超出大小限制则响应413并中止处理链
+func (c *Context) checkBodyError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) && !c.IsAborted() {
+		c.AbortWithStatus(http.StatusRequestEntityTooLarge)
+	}
+
+	return err
+}
+
+// 获取当前生效的multipart内存限制
+func (c *Context) multipartMemory() int64 {
+	if c.maxMultipartMemory > 0 {
+		return c.maxMultipartMemory
+	}
+
+	return defaultMultipartMemory
+}
+
+// 按当前生效的内存限制解析普通表单和multipart表单
+func (c *Context) parseForm() error {
+	// 先单独解析普通表单，否则ParseMultipartForm会用ErrNotMultipart掩盖读取错误
+	err := c.r.ParseForm()
+	if err == nil {
+		err = c.r.ParseMultipartForm(c.multipartMemory())
+	}
+
+	// 这里由于接收的是通用表单，所以忽略ErrNotMultipart
+	if errors.Is(err, http.ErrNotMultipart) {
+		return nil
+	}
+
+	return c.checkBodyError(err)
+}
+
 // 初始化PostFormCache
 func (c *Context) initPostFormCache() {
 	if c.r != nil {
-		err := c.r.ParseMultipartForm(defaultMultipartMaxMemory)
-		if err != nil {
-			// 这里由于接收的是通用表单，所以忽略ErrNotMultipart
-			if !errors.Is(err, http.ErrNotMultipart) {
-				c.e.logger.Error(err.Error())
-			}
+		if err := c.parseForm(); err != nil {
+			c.e.logger.Error(err.Error())
 		}
 		c.formCache = c.r.PostForm
 	} else {
@@ -86,18 +239,21 @@ func (c *Context) initPostFormCache() {
 
 // GetMultipartForm 获取原始MultipartForm
 func (c *Context) GetMultipartForm() (*multipart.Form, error) {
-	err := c.r.ParseMultipartForm(defaultMultipartMaxMemory)
+	c.checkReleased()
+	err := c.checkBodyError(c.r.ParseMultipartForm(c.multipartMemory()))
 	return c.r.MultipartForm, err
 }
 
 // GetPostForm 获取postForm
 func (c *Context) GetPostForm(key string) any {
+	c.checkReleased()
 	c.initPostFormCache()
 	return c.formCache.Get(key)
 }
 
 // GetPostFormArray 获取postForm切片
 func (c *Context) GetPostFormArray(key string) ([]string, bool) {
+	c.checkReleased()
 	c.initPostFormCache()
 	val, ok := c.formCache[key]
 	return val, ok
@@ -105,19 +261,92 @@ func (c *Context) GetPostFormArray(key string) ([]string, bool) {
 
 // GetFormFile 获取表单文件，返回文件头
 func (c *Context) GetFormFile(key string) (*multipart.FileHeader, error) {
-	_, header, err := c.r.FormFile(key)
-	return header, err
+	c.checkReleased()
+	form, err := c.GetMultipartForm()
+	if err != nil {
+		return nil, err
+	}
+
+	files := form.File[key]
+	if len(files) == 0 {
+		return nil, http.ErrMissingFile
+	}
+
+	return files[0], nil
+}
+
+// GetFormFiles 获取同一字段下的多个表单文件
+func (c *Context) GetFormFiles(key string) ([]*multipart.FileHeader, error) {
+	c.checkReleased()
+	form, err := c.GetMultipartForm()
+	if err != nil {
+		return nil, err
+	}
+
+	files := form.File[key]
+	if len(files) == 0 {
+		return nil, http.ErrMissingFile
+	}
+
+	return files, nil
+}
+
+// MultipartReader 获取multipart流式读取器，文件不会缓存到内存或临时文件，
+// 与GetMultipartForm、GetPostForm等方法互斥
+func (c *Context) MultipartReader() (*multipart.Reader, error) {
+	c.checkReleased()
+	return c.r.MultipartReader()
+}
+
+// StreamFormFile 将指定字段的上传文件直接写入dst，返回文件名和写入的字节数
+func (c *Context) StreamFormFile(key string, dst io.Writer) (string, int64, error) {
+	reader, err := c.MultipartReader()
+	if err != nil {
+		return "", 0, err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return "", 0, http.ErrMissingFile
+		}
+		if err != nil {
+			return "", 0, c.checkBodyError(err)
+		}
+
+		if part.FormName() != key || part.FileName() == "" {
+			_ = part.Close()
+			continue
+		}
+
+		n, err := io.Copy(dst, part)
+		_ = part.Close()
+		return part.FileName(), n, c.checkBodyError(err)
+	}
 }
 
-// SaveUploadFile 封装文件上传并保存的方法
+// ErrUnsafeUploadPath 上传文件的保存路径中包含路径穿越
+var ErrUnsafeUploadPath = errors.New("PoliteDog: unsafe upload file path")
+
+// SaveUploadFile 封装文件上传并保存的方法，会自动创建目录。
+// savePath以路径分隔符结尾或为已存在的目录时，使用清理后的上传文件名保存到该目录下
 func (c *Context) SaveUploadFile(fileHeader *multipart.FileHeader, savePath string) error {
+	dstPath, err := uploadFilePath(fileHeader.Filename, savePath)
+	if err != nil {
+		return err
+	}
+
 	src, err := fileHeader.Open()
 	if err != nil {
 		return err
 	}
 	defer src.Close()
 
-	dst, err := os.Create(savePath)
+	if err = os.MkdirAll(filepath.Dir(dstPath), 0750); err != nil {
+		return err
+	}
+
+	dst, err := os.Create(dstPath)
 	if err != nil {
 		return err
 	}
@@ -131,14 +360,151 @@ func (c *Context) SaveUploadFile(fileHeader *multipart.FileHeader, savePath stri
 数据绑定
 */
 
-func (c *Context) MustBindWith(obj any, binding binding.Binding) error {
-	return binding.Bind(c.r, obj)
+// ErrBodyTooLarge 请求体超出缓存上限
+var ErrBodyTooLarge = errors.New("PoliteDog: request body exceeds the cache limit")
+
+// BodyBytes 读取并缓存请求体，之后可以重复调用，请求体也可以被其它方法再次读取。
+// 超出引擎MaxBodyCacheBytes时返回ErrBodyTooLarge，此时请求体保持可流式读取
+func (c *Context) BodyBytes() ([]byte, error) {
+	c.checkReleased()
+	if c.bodyCached {
+		return c.bodyCache, nil
+	}
+
+	limit := c.e.MaxBodyCacheBytes
+	if limit <= 0 {
+		limit = defaultMaxBodyCacheBytes
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.r.Body, limit+1))
+	if err != nil {
+		return nil, c.checkBodyError(err)
+	}
+
+	if int64(len(body)) > limit {
+		// 将已读取的部分放回，保证请求体仍然完整
+		c.r.Body = readCloser{io.MultiReader(bytes.NewReader(body), c.r.Body), c.r.Body}
+		return nil, ErrBodyTooLarge
+	}
+
+	c.bodyCache = body
+	c.bodyCached = true
+	c.r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// 基于编解码器的Binding替换为引擎中注册的编解码器
+func (c *Context) engineBinding(b binding.Binding) binding.Binding {
+	if cb, ok := b.(binding.CodecBinding); ok {
+		if cd := c.e.Codec(cb.MediaType()); cd != nil {
+			return cb.WithCodec(cd)
+		}
+	}
+
+	return b
 }
 
-// BindJSON 解析JSON参数
+// MustBindWith 使用指定的Binding绑定，只返回错误，不写入响应也不中止处理链
+func (c *Context) MustBindWith(obj any, b binding.Binding) error {
+	return c.bindRequest(c.r, obj, b)
+}
+
+// 使用指定的请求绑定，r可以是附加了路由参数等信息的c.r副本
+func (c *Context) bindRequest(r *http.Request, obj any, b binding.Binding) error {
+	c.checkReleased()
+	if err := c.preParseForm(b); err != nil {
+		return err
+	}
+
+	return c.checkBodyError(c.engineBinding(b).Bind(r, obj))
+}
+
+// 表单绑定和读取multipart请求体的自定义Binding在binding包中使用默认的内存限制解析表单，
+// 这里先按引擎和路由的内存限制解析，解析结果缓存在请求上，binding包不会重复解析
+func (c *Context) preParseForm(b binding.Binding) error {
+	switch b {
+	case binding.QueryBind, binding.HeaderBind, binding.URIBind:
+		return nil
+	case binding.FormBind, binding.MultipartBind:
+		return c.parseForm()
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(c.r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		return c.parseForm()
+	}
+	return nil
+}
+
+// ShouldBindBodyWith 使用缓存的请求体绑定，同一请求可以多次绑定到不同的结构体
+func (c *Context) ShouldBindBodyWith(obj any, bb binding.BindingBody) error {
+	body, err := c.BodyBytes()
+	if err != nil {
+		return err
+	}
+
+	if eb, ok := c.engineBinding(bb).(binding.BindingBody); ok {
+		bb = eb
+	}
+
+	return bb.BindBody(body, obj)
+}
+
+// ShouldBind 根据请求方法和Content-Type自动选择Binding，
+// 无法匹配时返回binding.ErrUnsupportedMediaType，只返回错误，不写入响应
+func (c *Context) ShouldBind(obj any) error {
+	c.checkReleased()
+	contentType := c.r.Header.Get("Content-Type")
+
+	switch b := binding.Default(c.Method, contentType); b {
+	case nil:
+		return &binding.UnsupportedMediaTypeError{ContentType: contentType}
+	case binding.JSONBind:
+		return c.BindJSON(obj)
+	case binding.FormBind:
+		return c.BindForm(obj)
+	case binding.MultipartBind:
+		return c.BindMultipart(obj)
+	default:
+		return c.MustBindWith(obj, b)
+	}
+}
+
+// Bind 与ShouldBind相同，绑定失败时会根据错误响应415、422或400并中止处理链，
+// 是Bind系列中唯一会写入响应的方法
+func (c *Context) Bind(obj any) error {
+	return c.abortBind(c.ShouldBind(obj))
+}
+
+// 绑定失败时按错误类型响应对应的状态码并中止处理链
+func (c *Context) abortBind(err error) error {
+	if err != nil && !c.IsAborted() {
+		_ = c.AbortWithError(bindErrorStatus(err), err)
+	}
+
+	return err
+}
+
+// 获取绑定错误对应的状态码
+func bindErrorStatus(err error) int {
+	var validationErrs binding.ValidationErrors
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, binding.ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	case errors.As(err, &maxBytesErr), errors.Is(err, binding.ErrTooManyItems):
+		return http.StatusRequestEntityTooLarge
+	case errors.As(err, &validationErrs):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// BindJSON 解析JSON参数，与BindXML、BindQuery等一样只返回错误，不写入响应，需要自动响应时使用Bind
 func (c *Context) BindJSON(obj any) error {
-	jsonBind := binding.JSONBind
-	jsonBind.DisallowUnknownFields = c.DisallowUnknownFields
+	// 每次创建独立的Binding，避免并发请求修改共享的JSONBind
+	jsonBind := binding.NewJSONBinding(nil, c.DisallowUnknownFields)
 	return c.MustBindWith(obj, jsonBind)
 }
 
@@ -148,32 +514,139 @@ func (c *Context) BindXML(obj any) error {
 	return c.MustBindWith(obj, xmlBind)
 }
 
+// BindQuery 按form标签将query参数绑定到结构体
+func (c *Context) BindQuery(obj any) error {
+	return c.MustBindWith(obj, binding.QueryBind)
+}
+
+// BindForm 按form标签将query和表单参数绑定到结构体
+func (c *Context) BindForm(obj any) error {
+	return c.MustBindWith(obj, binding.FormBind)
+}
+
+// BindURI 按uri标签将路由参数绑定到结构体
+func (c *Context) BindURI(obj any) error {
+	params := make(map[string][]string, len(c.Params))
+	for _, p := range c.Params {
+		params[p.Key] = append(params[p.Key], p.Value)
+	}
+
+	// 将路由参数附加到请求的副本上，自定义Binding也可以通过binding.URIParams获取
+	return c.bindRequest(binding.WithURIParams(c.r, params), obj, binding.URIBind)
+}
+
+// BindHeader 按header标签将请求头绑定到结构体
+func (c *Context) BindHeader(obj any) error {
+	return c.MustBindWith(obj, binding.HeaderBind)
+}
+
+// BindMultipart 将multipart表单中的文本字段和文件一并绑定到结构体，
+// 文件字段的类型为*multipart.FileHeader或[]*multipart.FileHeader
+func (c *Context) BindMultipart(obj any) error {
+	return c.MustBindWith(obj, binding.MultipartBind)
+}
+
+/**
+客户端信息
+*/
+
+// RemoteIP 获取直连对端的IP
+func (c *Context) RemoteIP() string {
+	c.checkReleased()
+	ip, _, err := net.SplitHostPort(strings.TrimSpace(c.r.RemoteAddr))
+	if err != nil {
+		return strings.TrimSpace(c.r.RemoteAddr)
+	}
+
+	return ip
+}
+
+// ClientIP 获取客户端IP，仅当直连对端为受信任代理时才解析Forwarded、X-Forwarded-For等请求头
+func (c *Context) ClientIP() string {
+	remoteIP := c.RemoteIP()
+	if !c.e.isTrustedProxy(net.ParseIP(remoteIP)) {
+		return remoteIP
+	}
+
+	for _, header := range c.e.RemoteIPHeaders {
+		value := c.r.Header.Get(header)
+		if value == "" {
+			continue
+		}
+
+		if ip, ok := c.e.clientIPFromHeader(header, value); ok {
+			return ip
+		}
+	}
+
+	return remoteIP
+}
+
 /**
 上下文操作
 */
 
 // Next 将上下文移交给下一个handler
 func (c *Context) Next() {
+	c.checkReleased()
 	c.index++
 	for ; c.index < len(c.handlers); c.index++ {
 		c.handlers[c.index](c)
 	}
 }
 
-// Abort 进入引擎处理上下文
+// Abort 中止处理链，后续的中间件和handler不再执行，但引擎的收尾处理（如日志）仍会执行
 func (c *Context) Abort() {
-	c.index = len(c.handlers) - 2
-	c.handlers[c.index](c)
+	c.checkReleased()
+	c.index = abortIndex
+}
+
+// IsAborted 判断处理链是否已被中止
+func (c *Context) IsAborted() bool {
+	c.checkReleased()
+	return c.index >= abortIndex
 }
 
-// Status 返回状态码
+// AbortWithStatus 写入状态码并中止处理链
+func (c *Context) AbortWithStatus(code int) {
+	c.Status(code)
+	c.Abort()
+}
+
+// AbortWithStatusJSON 响应JSON数据并中止处理链
+func (c *Context) AbortWithStatusJSON(code int, data any) error {
+	c.Abort()
+	return c.JSON(code, data)
+}
+
+// AbortWithError 写入状态码并中止处理链，错误会被记录到Errors中
+func (c *Context) AbortWithError(code int, err error) error {
+	c.AbortWithStatus(code)
+	if err != nil {
+		c.Errors = append(c.Errors, err)
+	}
+
+	return err
+}
+
+// Status 返回状态码，只记录第一次写入的状态码，与客户端实际收到的一致
 func (c *Context) Status(code int) {
-	c.Code = code
+	c.checkReleased()
+	if c.Code == 0 {
+		c.Code = code
+	}
 	c.w.WriteHeader(code)
 }
 
+// Writer 获取响应写入器，用于流式响应，可通过http.NewResponseController(w).Flush()刷新
+func (c *Context) Writer() http.ResponseWriter {
+	c.checkReleased()
+	return c.w
+}
+
 // SetHeader 设置响应头
 func (c *Context) SetHeader(key string, value string) {
+	c.checkReleased()
 	c.w.Header().Set(key, value)
 }
 
@@ -183,9 +656,18 @@ func (c *Context) SetHeader(key string, value string) {
 
 // Render 渲染器
 func (c *Context) Render(w http.ResponseWriter, r render.Render) error {
+	c.checkReleased()
 	return r.Render(w)
 }
 
+// 先设置Content-Type再写入状态码，写入状态码之后设置的响应头不会生效
+func (c *Context) renderStatus(code int, r render.Render) error {
+	c.checkReleased()
+	r.WriteContentType(c.w)
+	c.Status(code)
+	return c.Render(c.w, r)
+}
+
 // Data 响应数据
 func (c *Context) Data(code int, data []byte) error {
 	c.Status(code)
@@ -193,6 +675,31 @@ func (c *Context) Data(code int, data []byte) error {
 	return err
 }
 
+// DataFromReader 从io.Reader流式响应数据，不会将内容整体读入内存，contentLength小于0时使用分块传输
+func (c *Context) DataFromReader(code int, contentLength int64, contentType string, reader io.Reader, extraHeaders map[string]string) error {
+	c.checkReleased()
+	r := &render.ReaderRender{
+		ContentType:   contentType,
+		ContentLength: contentLength,
+		Reader:        reader,
+		Headers:       extraHeaders,
+	}
+
+	// 响应头需要在写入状态码之前设置
+	r.WriteContentType(c.w)
+	c.Status(code)
+	return c.Render(c.w, r)
+}
+
+// ServeContent 响应可随机读取的内容，支持Range、If-Range、If-None-Match和If-Modified-Since，
+// 状态码由请求头决定（200、206、304、412、416等）
+func (c *Context) ServeContent(name string, modtime time.Time, content io.ReadSeeker) {
+	c.checkReleased()
+	sw := &statusWriter{ResponseWriter: c.w}
+	http.ServeContent(sw, c.r, name, modtime, content)
+	c.Code = sw.status
+}
+
 // HTML 响应HTML文本
 func (c *Context) HTML(code int, html string) error {
 	c.Status(code)
@@ -202,28 +709,35 @@ func (c *Context) HTML(code int, html string) error {
 	})
 }
 
-// HTMLTemplate 响应HTML模板
+// HTMLTemplate 响应HTML模板，name为通过AddTemplateSet注册的模板组名称时使用该模板组，
+// 否则使用LoadTemplate加载的模板。调试模式下模板文件变化时会重新解析，解析错误会输出到浏览器和日志
 func (c *Context) HTMLTemplate(code int, name string, data any) error {
-	c.Status(code)
-	return c.Render(c.w, &render.HTMLRender{
-		Name:     name,
-		Data:     data,
-		Template: c.e.HTMLRender.Template,
-		IsTmpl:   true,
+	engine, entry, err := c.e.lookupTemplate(name)
+	if err != nil {
+		return c.templateError(err)
+	}
+
+	return c.renderStatus(code, &render.HTMLRender{
+		Name:   entry,
+		Data:   data,
+		Engine: engine,
+		IsTmpl: true,
 	})
 }
 
+// HTMLTemplateGlob 使用glob匹配的模板响应，模板在首次使用时解析并缓存
 func (c *Context) HTMLTemplateGlob(code int, name string, data interface{}, pattern string) error {
-	c.Status(code)
-
-	tmpl := template.New(name)
-	tmpl, err := tmpl.ParseGlob(pattern)
+	engine, err := c.e.globTemplate(pattern)
 	if err != nil {
-		return err
+		return c.templateError(err)
 	}
 
-	err = tmpl.Execute(c.w, data)
-	return err
+	return c.renderStatus(code, &render.HTMLRender{
+		Name:   name,
+		Data:   data,
+		Engine: engine,
+		IsTmpl: true,
+	})
 }
 
 // String 响应纯文本
@@ -238,19 +752,90 @@ func (c *Context) String(code int, format string, data ...any) error {
 
 // JSON 响应JSON数据
 func (c *Context) JSON(code int, data any) error {
-	c.Status(code)
+	return c.renderStatus(code, &render.JSONRender{
+		Data:  data,
+		Codec: c.e.Codec(binding.MIMEJSON),
+	})
+}
+
+// IndentedJSON 响应缩进格式的JSON数据，便于调试
+func (c *Context) IndentedJSON(code int, data any) error {
+	return c.renderStatus(code, &render.IndentedJSONRender{
+		Data:  data,
+		Codec: c.e.Codec(binding.MIMEJSON),
+	})
+}
+
+// SecureJSON 响应带有防劫持前缀的JSON数据，前缀由Dog.SecureJSONPrefix设置
+func (c *Context) SecureJSON(code int, data any) error {
+	return c.renderStatus(code, &render.SecureJSONRender{
+		Prefix: c.e.SecureJSONPrefix,
+		Data:   data,
+		Codec:  c.e.Codec(binding.MIMEJSON),
+	})
+}
+
+// JSONP 响应JSONP数据，回调函数名取自query中的callback参数，为空时响应普通JSON，
+// 回调函数名不合法时响应400并返回render.ErrInvalidCallback
+func (c *Context) JSONP(code int, data any) error {
+	callback, _ := c.GetQuery("callback").(string)
+	if callback != "" && !render.IsValidCallback(callback) {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return render.ErrInvalidCallback
+	}
+
+	return c.renderStatus(code, &render.JSONPRender{
+		Callback: callback,
+		Data:     data,
+		Codec:    c.e.Codec(binding.MIMEJSON),
+	})
+}
 
-	return c.Render(c.w, &render.JSONRender{
-		Data: data,
+// AsciiJSON 响应将非ASCII字符转义后的JSON数据
+func (c *Context) AsciiJSON(code int, data any) error {
+	return c.renderStatus(code, &render.AsciiJSONRender{
+		Data:  data,
+		Codec: c.e.Codec(binding.MIMEJSON),
+	})
+}
+
+// PureJSON 响应不转义HTML字符的JSON数据
+func (c *Context) PureJSON(code int, data any) error {
+	return c.renderStatus(code, &render.PureJSONRender{
+		Data:  data,
+		Codec: c.e.Codec(binding.MIMEJSON),
 	})
 }
 
 // XML 响应xml数据
 func (c *Context) XML(code int, data any) error {
-	c.Status(code)
+	return c.renderStatus(code, &render.XMLRender{
+		Data:  data,
+		Codec: c.e.Codec(binding.MIMEXML),
+	})
+}
 
-	return c.Render(c.w, &render.XMLRender{
-		Data: data,
+// YAML 响应yaml数据
+func (c *Context) YAML(code int, data any) error {
+	return c.renderStatus(code, &render.YAMLRender{
+		Data:  data,
+		Codec: c.e.Codec(binding.MIMEYAML),
+	})
+}
+
+// TOML 响应toml数据
+func (c *Context) TOML(code int, data any) error {
+	return c.renderStatus(code, &render.TOMLRender{
+		Data:  data,
+		Codec: c.e.Codec(binding.MIMETOML),
+	})
+}
+
+// MsgPack 响应MessagePack数据
+func (c *Context) MsgPack(code int, data any) error {
+	return c.renderStatus(code, &render.MsgPackRender{
+		Data:  data,
+		Codec: c.e.Codec(binding.MIMEMsgPack),
 	})
 }
 
@@ -283,6 +868,7 @@ func (c *Context) FileAttachment(code int, filename string, filepath string) {
 
 // FileFromFS 从文件系统获取下载（filepath是相对于文件系统的路径）
 func (c *Context) FileFromFS(code int, filepath string, fs http.FileSystem) {
+	c.checkReleased()
 	defer func(oldPath string) {
 		c.r.URL.Path = oldPath
 	}(c.r.URL.Path)
@@ -299,6 +885,7 @@ func (c *Context) FileFromFS(code int, filepath string, fs http.FileSystem) {
 
 // SetKey 设置密钥
 func (c *Context) SetKey(keyName string, keyValue any) {
+	c.checkReleased()
 	c.m.Lock()
 	if c.Keys == nil {
 		c.Keys = make(map[string]any)
@@ -309,15 +896,98 @@ func (c *Context) SetKey(keyName string, keyValue any) {
 
 // GetKey 获取密钥
 func (c *Context) GetKey(keyName string) (any, bool) {
-	c.m.Lock()
+	c.checkReleased()
+	c.m.RLock()
 	keyValue, ok := c.Keys[keyName]
-	c.m.Unlock()
+	c.m.RUnlock()
 
 	return keyValue, ok
 }
 
+// MustGet 获取密钥，不存在时panic
+func (c *Context) MustGet(keyName string) any {
+	if keyValue, ok := c.GetKey(keyName); ok {
+		return keyValue
+	}
+
+	panic("PoliteDog: key \"" + keyName + "\" does not exist")
+}
+
+// GetString 获取string类型的密钥，不存在或类型不符时返回零值
+func (c *Context) GetString(keyName string) string {
+	keyValue, _ := c.GetKey(keyName)
+	str, _ := keyValue.(string)
+	return str
+}
+
+// GetInt 获取int类型的密钥，不存在或类型不符时返回零值
+func (c *Context) GetInt(keyName string) int {
+	keyValue, _ := c.GetKey(keyName)
+	i, _ := keyValue.(int)
+	return i
+}
+
+// GetBool 获取bool类型的密钥，不存在或类型不符时返回零值
+func (c *Context) GetBool(keyName string) bool {
+	keyValue, _ := c.GetKey(keyName)
+	b, _ := keyValue.(bool)
+	return b
+}
+
+// GetDuration 获取time.Duration类型的密钥，不存在或类型不符时返回零值
+func (c *Context) GetDuration(keyName string) time.Duration {
+	keyValue, _ := c.GetKey(keyName)
+	d, _ := keyValue.(time.Duration)
+	return d
+}
+
 // SetBasicAuth 设置Basic认证
 func (c *Context) SetBasicAuth(username string, password string) {
+	c.checkReleased()
 	encodeStr := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
 	c.w.Header().Set("Authorization", "Basic "+encodeStr)
 }
+
+// 组合读取和关闭
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// 记录响应状态码的写入器
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Copy得到的上下文使用的响应写入器，丢弃所有写入
+type readOnlyWriter struct {
+	header http.Header
+}
+
+var errCopiedContext = errors.New("PoliteDog: can not write response with a copied Context")
+
+func (w readOnlyWriter) Header() http.Header {
+	return w.header
+}
+
+func (w readOnlyWriter) Write([]byte) (int, error) {
+	return 0, errCopiedContext
+}
+
+func (w readOnlyWriter) WriteHeader(int) {
+}
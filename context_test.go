@@ -0,0 +1,548 @@
+package PoliteDog
+
+import (
+	"bytes"
+	"errors"
+	"github.com/fangnan700/PoliteDog/binding"
+	"github.com/fangnan700/PoliteDog/codec"
+	"github.com/fangnan700/PoliteDog/render"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContext_ClientIP(t *testing.T) {
+	dog := NewDog()
+	if err := dog.SetTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name       string
+		remoteAddr string
+		header     string
+		value      string
+		want       string
+	}{
+		{"untrusted peer", "8.8.8.8:1234", "X-Forwarded-For", "1.1.1.1", "8.8.8.8"},
+		{"x-forwarded-for", "10.0.0.1:1234", "X-Forwarded-For", "1.1.1.1, 10.0.0.2", "1.1.1.1"},
+		{"spoofed chain", "10.0.0.1:1234", "X-Forwarded-For", "6.6.6.6, 1.1.1.1", "1.1.1.1"},
+		{"x-real-ip", "192.168.1.1:80", "X-Real-IP", "2.2.2.2", "2.2.2.2"},
+		{"forwarded", "10.0.0.1:1234", "Forwarded", `for="[2001:db8::1]:4711";proto=https, for=10.0.0.3`, "2001:db8::1"},
+		{"invalid header", "10.0.0.1:1234", "X-Forwarded-For", "unknown", "10.0.0.1"},
+	}
+
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = tc.remoteAddr
+		r.Header.Set(tc.header, tc.value)
+
+		ctx := &Context{e: dog, r: r}
+		if got := ctx.ClientIP(); got != tc.want {
+			t.Errorf("%s: ClientIP() = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	// 各引擎的请求头列表互不影响
+	dog.RemoteIPHeaders[0] = "X-Custom-IP"
+	if got := NewDog().RemoteIPHeaders[0]; got != "Forwarded" {
+		t.Errorf("RemoteIPHeaders of a new engine = %q, want Forwarded", got)
+	}
+}
+
+func TestContext_Copy(t *testing.T) {
+	dog := NewDog()
+	dog.SetMode(DebugMode)
+
+	var leaked, copied *Context
+	router := NewRouter()
+	router.GET("/user/info", func(ctx *Context) {
+		ctx.SetKey("user", "admin")
+		leaked = ctx
+		copied = ctx.Copy()
+	})
+	dog.RegisterRouters(router)
+	dog.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/user/info?page=2", nil))
+
+	if v, _ := copied.GetKey("user"); v != "admin" {
+		t.Errorf("copied key = %v, want admin", v)
+	}
+	if got := copied.GetQuery("page"); got != "2" {
+		t.Errorf("copied query = %v, want 2", got)
+	}
+	if got := copied.FullPath(); got != "/user/info" {
+		t.Errorf("copied FullPath() = %q, want /user/info", got)
+	}
+
+	uses := map[string]func(){
+		"GetKey":       func() { leaked.GetKey("user") },
+		"SetHeader":    func() { leaked.SetHeader("X-Leaked", "1") },
+		"Redirect":     func() { _ = leaked.Redirect(http.StatusFound, "/") },
+		"Abort":        func() { leaked.Abort() },
+		"IsAborted":    func() { leaked.IsAborted() },
+		"SetBasicAuth": func() { leaked.SetBasicAuth("admin", "secret") },
+		"FileFromFS":   func() { leaked.FileFromFS(http.StatusOK, "/x", http.Dir(".")) },
+	}
+	for name, use := range uses {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s on a released Context did not panic in debug mode", name)
+				}
+			}()
+			use()
+		}()
+	}
+}
+
+func TestContext_Keys(t *testing.T) {
+	ctx := &Context{}
+	ctx.SetKey("name", "admin")
+	ctx.SetKey("age", 21)
+
+	if got := ctx.GetString("name"); got != "admin" {
+		t.Errorf("GetString() = %q, want admin", got)
+	}
+	if got := ctx.GetInt("age"); got != 21 {
+		t.Errorf("GetInt() = %d, want 21", got)
+	}
+	if got := ctx.GetBool("name"); got {
+		t.Error("GetBool() on a string key = true, want false")
+	}
+
+	userID := NewKey[int64]("user")
+	otherUser := NewKey[string]("user")
+	Set(ctx, userID, 42)
+	Set(ctx, otherUser, "guest")
+
+	if got, ok := Get(ctx, userID); !ok || got != 42 {
+		t.Errorf("Get(userID) = %v, %v, want 42, true", got, ok)
+	}
+	if got, _ := Get(ctx, otherUser); got != "guest" {
+		t.Errorf("Get(otherUser) = %q, want guest", got)
+	}
+
+	lastErr := NewKey[error]("err")
+	Set(ctx, lastErr, nil)
+	if got, ok := Get(ctx, lastErr); !ok || got != nil {
+		t.Errorf("Get(lastErr) = %v, %v, want nil, true", got, ok)
+	}
+}
+
+func TestContext_ServeContent(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/export", nil)
+	r.Header.Set("Range", "bytes=6-10")
+	w := httptest.NewRecorder()
+
+	ctx := &Context{e: NewDog(), w: w, r: r}
+	ctx.ServeContent("export.txt", time.Now(), strings.NewReader("hello world"))
+
+	if ctx.Code != http.StatusPartialContent || w.Code != http.StatusPartialContent {
+		t.Errorf("status = %d/%d, want %d", ctx.Code, w.Code, http.StatusPartialContent)
+	}
+	if got := w.Body.String(); got != "world" {
+		t.Errorf("body = %q, want world", got)
+	}
+}
+
+func TestContext_DataFromReader(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx := &Context{e: NewDog(), w: w, r: httptest.NewRequest(http.MethodGet, "/", nil)}
+
+	err := ctx.DataFromReader(http.StatusOK, 5, "text/csv", strings.NewReader("a,b,c"), map[string]string{
+		"Content-Disposition": `attachment; filename="export.csv"`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/csv" {
+		t.Errorf("Content-Type = %q, want text/csv", got)
+	}
+	if got := w.Header().Get("Content-Length"); got != "5" {
+		t.Errorf("Content-Length = %q, want 5", got)
+	}
+	if got := w.Body.String(); got != "a,b,c" {
+		t.Errorf("body = %q, want a,b,c", got)
+	}
+}
+
+func TestContext_MaxBodyBytes(t *testing.T) {
+	dog := NewDog()
+	router := NewRouter()
+	router.MaxBodyBytes = 8
+	router.POST("/upload", func(ctx *Context) {
+		ctx.GetPostForm("name")
+		if !ctx.IsAborted() {
+			ctx.Status(http.StatusOK)
+		}
+	})
+	dog.RegisterRouters(router)
+
+	// Content-Length已知时直接拒绝
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("name=politedog"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	dog.ServeHTTP(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+
+	// 分块传输时在读取过程中拒绝
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("name=politedog"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.ContentLength = -1
+	dog.ServeHTTP(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("chunked status = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestUploadFilePath(t *testing.T) {
+	dir := t.TempDir()
+
+	if got, err := uploadFilePath(`..\..\evil.sh`, dir+"/"); err != nil || got != filepath.Join(dir, "evil.sh") {
+		t.Errorf("uploadFilePath() = %q, %v, want %q", got, err, filepath.Join(dir, "evil.sh"))
+	}
+	if _, err := uploadFilePath("a.txt", dir+"/../a.txt"); !errors.Is(err, ErrUnsafeUploadPath) {
+		t.Errorf("uploadFilePath() error = %v, want ErrUnsafeUploadPath", err)
+	}
+	if _, err := uploadFilePath("..", dir); !errors.Is(err, ErrUnsafeUploadPath) {
+		t.Errorf("uploadFilePath() error = %v, want ErrUnsafeUploadPath", err)
+	}
+}
+
+func TestContext_ShouldBindBodyWith(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"admin","age":21}`))
+	ctx := &Context{e: NewDog(), w: httptest.NewRecorder(), r: r}
+
+	var user struct {
+		Name string `json:"name"`
+	}
+	var profile struct {
+		Age int `json:"age"`
+	}
+
+	if err := ctx.ShouldBindBodyWith(&user, binding.JSONBind); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.ShouldBindBodyWith(&profile, binding.JSONBind); err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != "admin" || profile.Age != 21 {
+		t.Errorf("got %+v %+v", user, profile)
+	}
+
+	// 缓存后请求体仍可被再次读取
+	body, _ := io.ReadAll(ctx.r.Body)
+	if string(body) != `{"name":"admin","age":21}` {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestContext_BodyBytesLimit(t *testing.T) {
+	dog := NewDog()
+	dog.MaxBodyCacheBytes = 4
+	ctx := &Context{e: dog, r: httptest.NewRequest(http.MethodPost, "/", strings.NewReader("politedog"))}
+
+	if _, err := ctx.BodyBytes(); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("BodyBytes() error = %v, want ErrBodyTooLarge", err)
+	}
+
+	body, _ := io.ReadAll(ctx.r.Body)
+	if string(body) != "politedog" {
+		t.Errorf("body after limit = %q, want politedog", body)
+	}
+}
+
+func TestContext_Bind(t *testing.T) {
+	type user struct {
+		Name string `json:"name" xml:"name" form:"name" binding:"required"`
+	}
+
+	cases := []struct {
+		contentType string
+		body        string
+		wantCode    int
+	}{
+		{"application/json; charset=utf-8", `{"name":"admin"}`, 0},
+		{"application/xml", `<user><name>admin</name></user>`, 0},
+		{"application/x-www-form-urlencoded", `name=admin`, 0},
+		{"application/yaml", "name: admin\n", 0},
+		{"application/toml", `name = "admin"`, 0},
+		{"application/msgpack", "\x81\xa4name\xa5admin", 0},
+		{"text/csv", `name`, http.StatusUnsupportedMediaType},
+		{"application/json", `{"name":""}`, http.StatusUnprocessableEntity},
+		{"application/json", `{"name":`, http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+		r.Header.Set("Content-Type", tc.contentType)
+		w := httptest.NewRecorder()
+		ctx := &Context{e: NewDog(), w: w, r: r, Method: r.Method}
+
+		var u user
+		err := ctx.Bind(&u)
+		if tc.wantCode == 0 {
+			if err != nil || u.Name != "admin" {
+				t.Errorf("%s: Bind() = %v, %+v", tc.contentType, err, u)
+			}
+			continue
+		}
+
+		if ctx.Code != tc.wantCode || !ctx.IsAborted() {
+			t.Errorf("%s: status = %d, aborted = %v, want %d", tc.contentType, ctx.Code, ctx.IsAborted(), tc.wantCode)
+		}
+
+		// 绑定失败后继续响应不会改变已写入的状态码
+		_ = ctx.String(http.StatusOK, "ok")
+		if ctx.Code != tc.wantCode || w.Code != tc.wantCode {
+			t.Errorf("%s: status after String = %d/%d, want %d", tc.contentType, ctx.Code, w.Code, tc.wantCode)
+		}
+	}
+}
+
+// 记录调用次数的JSON编解码器
+type countingCodec struct {
+	codec.Codec
+	decodes, marshals int
+}
+
+func (cc *countingCodec) NewDecoder(r io.Reader) codec.Decoder {
+	cc.decodes++
+	return cc.Codec.NewDecoder(r)
+}
+
+func (cc *countingCodec) Marshal(v any) ([]byte, error) {
+	cc.marshals++
+	return cc.Codec.Marshal(v)
+}
+
+func TestDog_RegisterCodec(t *testing.T) {
+	dog := NewDog()
+	cc := &countingCodec{Codec: codec.JSON}
+	dog.RegisterCodec("application/json", cc)
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"admin","extra":1}`))
+	r.Header.Set("Content-Type", "application/json")
+	ctx := &Context{e: dog, w: httptest.NewRecorder(), r: r, Method: r.Method, DisallowUnknownFields: true}
+
+	var user struct {
+		Name string `json:"name"`
+	}
+	if err := ctx.ShouldBind(&user); err == nil {
+		t.Error("ShouldBind() with unknown field returned nil error")
+	}
+	if err := ctx.JSON(http.StatusOK, user); err != nil {
+		t.Fatal(err)
+	}
+	if cc.decodes != 1 || cc.marshals != 1 {
+		t.Errorf("codec used %d/%d times, want 1/1", cc.decodes, cc.marshals)
+	}
+}
+
+func TestBindStream(t *testing.T) {
+	type record struct {
+		Name string `json:"name" binding:"required"`
+		Qty  int    `json:"qty"`
+	}
+
+	cases := []struct {
+		contentType string
+		body        string
+		maxItems    int
+		wantNames   string
+		wantCode    int
+		wantPath    string
+	}{
+		{"application/x-ndjson", "{\"name\":\"a\"}\n\n{\"name\":\"b\"}\n", 0, "ab", 0, ""},
+		{"application/json", ` [ {"name":"a"}, {"name":"b"} ] `, 2, "ab", 0, ""},
+		{"application/json", `[]`, 0, "", 0, ""},
+		{"application/json", `[{"name":"a"},{"name":""}]`, 0, "a", http.StatusUnprocessableEntity, "[1].Name"},
+		{"application/x-ndjson", "{\"name\":\"a\",\"qty\":\"x\"}", 0, "", http.StatusBadRequest, "[0].qty"},
+		{"application/x-ndjson", "{\"name\":\"a\"}\n{\"name\":\"b\"}", 1, "a", http.StatusRequestEntityTooLarge, ""},
+		{"application/json", `{"name":"a"}`, 0, "", http.StatusBadRequest, ""},
+		{"application/json", `[{"name":"a"}`, 0, "a", http.StatusBadRequest, ""},
+		{"text/csv", `a`, 0, "", http.StatusUnsupportedMediaType, ""},
+	}
+
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+		r.Header.Set("Content-Type", tc.contentType)
+		ctx := &Context{e: NewDog(), w: httptest.NewRecorder(), r: r, Method: r.Method}
+
+		names := ""
+		err := BindStream(ctx, tc.maxItems, func(item *record) error {
+			names += item.Name
+			return nil
+		})
+
+		if names != tc.wantNames {
+			t.Errorf("%q: names = %q, want %q", tc.body, names, tc.wantNames)
+		}
+		if tc.wantCode == 0 {
+			if err != nil {
+				t.Errorf("%q: err = %v", tc.body, err)
+			}
+			continue
+		}
+		if ctx.Code != tc.wantCode || !ctx.IsAborted() {
+			t.Errorf("%q: status = %d, want %d (err = %v)", tc.body, ctx.Code, tc.wantCode, err)
+		}
+
+		var bindErr *binding.BindError
+		var validationErrs binding.ValidationErrors
+		switch {
+		case tc.wantPath == "":
+		case errors.As(err, &bindErr):
+			if bindErr.Path != tc.wantPath {
+				t.Errorf("%q: path = %q, want %q", tc.body, bindErr.Path, tc.wantPath)
+			}
+		case errors.As(err, &validationErrs):
+			if validationErrs[0].Field != tc.wantPath {
+				t.Errorf("%q: path = %q, want %q", tc.body, validationErrs[0].Field, tc.wantPath)
+			}
+		default:
+			t.Errorf("%q: err = %v", tc.body, err)
+		}
+	}
+}
+
+func TestBindStream_HandlerError(t *testing.T) {
+	// 生成大量元素的请求体，元素逐个读取而不是整体读入内存
+	pr, pw := io.Pipe()
+	go func() {
+		for i := 0; i < 10000; i++ {
+			_, _ = io.WriteString(pw, `{"n":1}`+"\n")
+		}
+		_ = pw.Close()
+	}()
+
+	r := httptest.NewRequest(http.MethodPost, "/", pr)
+	r.Header.Set("Content-Type", "application/x-ndjson")
+	ctx := &Context{e: NewDog(), w: httptest.NewRecorder(), r: r, Method: r.Method}
+
+	stop := errors.New("stop")
+	count := 0
+	err := BindStream(ctx, 0, func(item *struct{ N int }) error {
+		count += item.N
+		if count == 5000 {
+			return stop
+		}
+		return nil
+	})
+
+	if err != stop || count != 5000 || ctx.IsAborted() {
+		t.Errorf("err = %v, count = %d, aborted = %v", err, count, ctx.IsAborted())
+	}
+	_ = pr.Close()
+}
+
+func TestContext_JSONVariants(t *testing.T) {
+	data := map[string]any{"html": "<b>&", "name": "狗🐶"}
+
+	cases := []struct {
+		name        string
+		target      string
+		render      func(ctx *Context) error
+		contentType string
+		body        string
+	}{
+		{"IndentedJSON", "/", func(ctx *Context) error { return ctx.IndentedJSON(http.StatusOK, []int{1}) },
+			"application/json; charset=utf-8", "[\n    1\n]"},
+		{"SecureJSON", "/", func(ctx *Context) error { return ctx.SecureJSON(http.StatusOK, []int{1}) },
+			"application/json; charset=utf-8", "while(1);[1]"},
+		{"JSONP", "/?callback=app.cb", func(ctx *Context) error { return ctx.JSONP(http.StatusOK, 1) },
+			"application/javascript; charset=utf-8", "/**/app.cb(1);"},
+		{"JSONP without callback", "/", func(ctx *Context) error { return ctx.JSONP(http.StatusOK, 1) },
+			"application/json; charset=utf-8", "1"},
+		{"AsciiJSON", "/", func(ctx *Context) error { return ctx.AsciiJSON(http.StatusOK, data) },
+			"application/json", `{"html":"\u003cb\u003e\u0026","name":"\u72d7\ud83d\udc36"}`},
+		{"PureJSON", "/", func(ctx *Context) error { return ctx.PureJSON(http.StatusOK, data) },
+			"application/json; charset=utf-8", `{"html":"<b>&","name":"狗🐶"}` + "\n"},
+	}
+
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		ctx := &Context{e: NewDog(), w: w, r: httptest.NewRequest(http.MethodGet, tc.target, nil)}
+		if err := tc.render(ctx); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		if got := w.Result().Header.Get("Content-Type"); got != tc.contentType {
+			t.Errorf("%s: Content-Type = %q, want %q", tc.name, got, tc.contentType)
+		}
+		if w.Body.String() != tc.body {
+			t.Errorf("%s: body = %q, want %q", tc.name, w.Body.String(), tc.body)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	ctx := &Context{e: NewDog(), w: w, r: httptest.NewRequest(http.MethodGet, "/?callback=alert(1)//", nil)}
+	if err := ctx.JSONP(http.StatusOK, 1); !errors.Is(err, render.ErrInvalidCallback) || w.Code != http.StatusBadRequest {
+		t.Errorf("JSONP with invalid callback: err = %v, status = %d", err, w.Code)
+	}
+}
+
+func TestContext_MustBindWithMultipartMemory(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("file", "a.txt")
+	_, _ = fw.Write(bytes.Repeat([]byte("a"), 1024))
+	_ = mw.Close()
+	payload := body.String()
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	ctx := &Context{e: NewDog(), w: httptest.NewRecorder(), r: r, maxMultipartMemory: 1}
+
+	var form struct {
+		File *multipart.FileHeader `form:"file"`
+	}
+	if err := ctx.MustBindWith(&form, binding.MultipartBind); err != nil {
+		t.Fatal(err)
+	}
+
+	// 超出内存限制的文件写入临时文件
+	f, err := form.File.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, ok := f.(*os.File); !ok {
+		t.Errorf("file kept in memory (%T), the route multipart memory limit was ignored", f)
+	}
+	_ = r.MultipartForm.RemoveAll()
+
+	// 按Binding本身而不是名称判断，与内置Binding同名的自定义Binding同样先解析multipart表单
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	ctx = &Context{e: NewDog(), w: httptest.NewRecorder(), r: r, maxMultipartMemory: 1}
+	if err = ctx.MustBindWith(&form, multipartNamedBinding{"query"}); err != nil {
+		t.Fatal(err)
+	}
+	_ = r.MultipartForm.RemoveAll()
+}
+
+// 读取已解析的multipart表单的自定义Binding
+type multipartNamedBinding struct {
+	name string
+}
+
+func (b multipartNamedBinding) Name() string {
+	return b.name
+}
+
+func (b multipartNamedBinding) Bind(r *http.Request, obj any) error {
+	if r.MultipartForm == nil {
+		return errors.New("multipart form was not parsed")
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package PoliteDog
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// 默认的解压后请求体大小限制
+const defaultDecompressMaxBytes = 32 << 20
+
+// DecompressOptions 请求体解压配置
+type DecompressOptions struct {
+	MaxBytes int64 // 解压后请求体的最大字节数，为0时为32MB，超出时响应413
+}
+
+// Decompress 请求体解压中间件，按Content-Encoding解压gzip和deflate编码的请求体，
+// 之后的Bind、GetPostForm、GetMultipartForm读取到的都是解压后的数据。
+// 不支持的编码响应415，压缩数据无效时响应400
+func Decompress(opts DecompressOptions) HandlerFuc {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = defaultDecompressMaxBytes
+	}
+
+	return func(ctx *Context) {
+		encodings := contentEncodings(ctx.r.Header.Get("Content-Encoding"))
+		if len(encodings) == 0 || ctx.r.Body == nil || ctx.r.Body == http.NoBody {
+			ctx.Next()
+			return
+		}
+
+		// 多个编码按应用的顺序列出，解码时从后往前
+		var body io.Reader = ctx.r.Body
+		for i := len(encodings) - 1; i >= 0; i-- {
+			var err error
+			switch encodings[i] {
+			case "gzip", "x-gzip":
+				body, err = gzip.NewReader(body)
+			case "deflate":
+				body, err = newDeflateReader(body)
+			default:
+				ctx.SetHeader("Accept-Encoding", "gzip, deflate")
+				_ = ctx.AbortWithError(http.StatusUnsupportedMediaType, fmt.Errorf("PoliteDog: unsupported Content-Encoding %q", encodings[i]))
+				return
+			}
+			if err != nil {
+				_ = ctx.AbortWithError(http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		ctx.r.Body = http.MaxBytesReader(ctx.w, readCloser{Reader: body, Closer: ctx.r.Body}, opts.MaxBytes)
+		ctx.r.ContentLength = -1
+		ctx.r.Header.Del("Content-Length")
+		ctx.r.Header.Del("Content-Encoding")
+
+		ctx.Next()
+	}
+}
+
+// 解析Content-Encoding，忽略identity
+func contentEncodings(header string) []string {
+	var encodings []string
+	for _, encoding := range strings.Split(header, ",") {
+		encoding = strings.ToLower(strings.TrimSpace(encoding))
+		if encoding != "" && encoding != "identity" {
+			encodings = append(encodings, encoding)
+		}
+	}
+
+	return encodings
+}
+
+// deflate按规范为zlib格式，但也有客户端发送原始的deflate数据，根据zlib头区分
+func newDeflateReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
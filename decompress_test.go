@@ -0,0 +1,88 @@
+package PoliteDog
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func compressBody(t *testing.T, encoding string, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	case "flate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	}
+	if _, err := io.WriteString(w, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	router := NewRouter()
+	router.POST("/json", func(ctx *Context) {
+		var u user
+		if err := ctx.Bind(&u); err != nil {
+			return
+		}
+		_ = ctx.String(http.StatusOK, u.Name)
+	})
+	router.POST("/form", func(ctx *Context) {
+		name, _ := ctx.GetPostForm("name").(string)
+		_ = ctx.String(http.StatusOK, name)
+	})
+
+	dog := NewDog()
+	dog.Use(Decompress(DecompressOptions{MaxBytes: 1024}))
+	dog.RegisterRouters(router)
+
+	cases := []struct {
+		name, path, contentType, encoding string
+		body                              []byte
+		code                              int
+		want                              string
+	}{
+		{"gzip json", "/json", "application/json", "gzip", compressBody(t, "gzip", `{"name":"dog"}`), http.StatusOK, "dog"},
+		{"zlib form", "/form", "application/x-www-form-urlencoded", "deflate", compressBody(t, "zlib", "name=dog"), http.StatusOK, "dog"},
+		{"raw deflate form", "/form", "application/x-www-form-urlencoded", "deflate", compressBody(t, "flate", "name=dog"), http.StatusOK, "dog"},
+		{"identity", "/json", "application/json", "identity", []byte(`{"name":"dog"}`), http.StatusOK, "dog"},
+		{"too large", "/json", "application/json", "gzip", compressBody(t, "gzip", `{"name":"`+strings.Repeat("a", 2048)+`"}`), http.StatusRequestEntityTooLarge, ""},
+		{"unknown", "/json", "application/json", "br", []byte(`{"name":"dog"}`), http.StatusUnsupportedMediaType, ""},
+		{"corrupt", "/json", "application/json", "gzip", []byte("not gzip"), http.StatusBadRequest, ""},
+	}
+
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodPost, tc.path, bytes.NewReader(tc.body))
+		r.Header.Set("Content-Type", tc.contentType)
+		r.Header.Set("Content-Encoding", tc.encoding)
+		w := httptest.NewRecorder()
+		dog.ServeHTTP(w, r)
+
+		if w.Code != tc.code {
+			t.Errorf("%s: code = %d, want %d", tc.name, w.Code, tc.code)
+		}
+		if tc.want != "" && w.Body.String() != tc.want {
+			t.Errorf("%s: body = %q, want %q", tc.name, w.Body.String(), tc.want)
+		}
+	}
+}
@@ -2,27 +2,57 @@ package PoliteDog
 
 import (
 	"fmt"
+	"github.com/fangnan700/PoliteDog/codec"
 	"github.com/fangnan700/PoliteDog/logger"
 	"github.com/fangnan700/PoliteDog/render"
 	"html/template"
+	"net"
 	"net/http"
+	"slices"
 	"sync"
 )
 
+// 运行模式
+const (
+	DebugMode   = "debug"
+	ReleaseMode = "release"
+)
+
 // Dog 核心引擎结构体
 type Dog struct {
 	pool         sync.Pool
 	logger       *logger.Logger
+	mode         string
 	Routers      []*Router
 	RouterGroups []*RouterGroup
 	Middlewares  []HandlerFuc
 	TmplFuncMap  template.FuncMap
-	HTMLRender   render.HTMLRender
+	HTMLEngine   render.HTMLEngine
+	templates    templateRegistry
+	assets       assetManifest
+	codecs       *codec.Registry
+
+	SecureJSONPrefix string // SecureJSON使用的防劫持前缀
+
+	// 请求体限制
+	MaxMultipartMemory int64 // 解析multipart表单时使用的最大内存，超出部分写入临时文件
+	MaxBodyBytes       int64 // 请求体的最大字节数，为0时不限制，超出时响应413
+	MaxBodyCacheBytes  int64 // BodyBytes允许缓存的最大字节数
+
+	// 客户端IP解析
+	trustedCIDRs    []*net.IPNet
+	RemoteIPHeaders []string // 仅当直连地址为受信任代理时，按顺序尝试的请求头
 }
 
 func NewDog() *Dog {
 	dog := &Dog{
-		Routers: make([]*Router, 0),
+		Routers:            make([]*Router, 0),
+		RemoteIPHeaders:    slices.Clone(defaultRemoteIPHeaders),
+		mode:               ReleaseMode,
+		MaxMultipartMemory: defaultMultipartMemory,
+		MaxBodyCacheBytes:  defaultMaxBodyCacheBytes,
+		codecs:             codec.NewRegistry(),
+		SecureJSONPrefix:   render.DefaultSecureJSONPrefix,
 	}
 	dog.pool.New = func() any {
 		return dog.allocateContext()
@@ -39,20 +69,60 @@ func (dog *Dog) allocateContext() any {
 	}
 }
 
+// SetMode 设置运行模式，调试模式下会检测上下文在请求结束后被继续使用的情况
+func (dog *Dog) SetMode(mode string) {
+	switch mode {
+	case DebugMode, ReleaseMode:
+		dog.mode = mode
+	default:
+		panic("PoliteDog: unknown mode: " + mode)
+	}
+}
+
+// IsDebugging 是否处于调试模式
+func (dog *Dog) IsDebugging() bool {
+	return dog.mode == DebugMode
+}
+
+// RegisterCodec 按媒体类型注册编解码器，绑定和渲染都会使用，只对当前引擎生效
+func (dog *Dog) RegisterCodec(mediaType string, c codec.Codec) {
+	dog.codecs.Register(mediaType, c)
+}
+
+// Codec 获取媒体类型对应的编解码器，未注册时返回nil
+func (dog *Dog) Codec(mediaType string) codec.Codec {
+	c, _ := dog.codecs.Lookup(mediaType)
+	return c
+}
+
 // SetFuncMap 设置模板渲染过程中可能使用的自定义函数
 func (dog *Dog) SetFuncMap(funcMap template.FuncMap) {
 	dog.TmplFuncMap = funcMap
 }
 
-// SetTemplate 允许开发者自己设置模板
+// SetTemplate 允许开发者自己设置模板，自行设置的模板不会在调试模式下重新加载
 func (dog *Dog) SetTemplate(tmpl *template.Template) {
-	dog.HTMLRender = render.HTMLRender{Template: tmpl}
+	_ = dog.SetHTMLEngine(render.NewHTMLTemplateEngine(tmpl))
 }
 
-// LoadTemplate 加载模板
-func (dog *Dog) LoadTemplate(pattern string) {
-	tmpl := template.Must(template.New("").Funcs(dog.TmplFuncMap).ParseGlob(pattern))
-	dog.SetTemplate(tmpl)
+// SetTrustedProxies 设置受信任的代理，支持IP和CIDR，传入nil表示不信任任何代理
+func (dog *Dog) SetTrustedProxies(proxies []string) error {
+	cidrs := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		cidr, err := parseTrustedProxy(proxy)
+		if err != nil {
+			return err
+		}
+		cidrs = append(cidrs, cidr)
+	}
+
+	dog.trustedCIDRs = cidrs
+	return nil
+}
+
+// Use 注册引擎级中间件，对所有匹配到路由的请求生效，在路由的中间件之前执行
+func (dog *Dog) Use(handler ...HandlerFuc) {
+	dog.Middlewares = append(dog.Middlewares, handler...)
 }
 
 // RegisterRouters 将路由注册到引擎
@@ -73,15 +143,19 @@ func (dog *Dog) RegisterRouterGroup(groups ...*RouterGroup) {
 // ServeHTTP
 func (dog *Dog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := dog.pool.Get().(*Context)
-	ctx.w = w
-	ctx.r = r
-	ctx.Method = r.Method
-	ctx.Path = r.URL.Path
-	ctx.index = -1
-	ctx.Code = 0
-	ctx.handlers = make([]HandlerFuc, 0)
+	ctx.reset(w, r)
 
 	dog.HttpRequestHandler(ctx)
+
+	// 调试模式下不回收上下文，以便可靠地检测请求结束后的非法使用
+	if dog.IsDebugging() {
+		ctx.released.Store(true)
+		return
+	}
+
+	// 归还对象池前清理密钥，避免残留到下一个请求
+	ctx.Keys = nil
+	ctx.typedKeys = nil
 	dog.pool.Put(ctx)
 }
 
@@ -90,9 +164,12 @@ func (dog *Dog) HttpRequestHandler(ctx *Context) {
 	path := ctx.r.URL.Path
 	matched := false
 	methodHit := false
+	maxBodyBytes := dog.MaxBodyBytes
+	ctx.maxMultipartMemory = dog.MaxMultipartMemory
 
-	// 注册异常捕获中间件
+	// 注册异常捕获中间件和引擎级中间件
 	ctx.handlers = append(ctx.handlers, Recovery)
+	ctx.handlers = append(ctx.handlers, dog.Middlewares...)
 
 	for _, router := range dog.Routers {
 		trieNode := router.RouterTrie.next.Search(path)
@@ -100,6 +177,7 @@ func (dog *Dog) HttpRequestHandler(ctx *Context) {
 		// 匹配到路由
 		if trieNode != nil && trieNode.end {
 			matched = true
+			ctx.fullPath = trieNode.path
 
 			// 根据key提取handler
 			key := trieNode.key
@@ -113,32 +191,36 @@ func (dog *Dog) HttpRequestHandler(ctx *Context) {
 			// 校验请求方法
 			if trieNode.method == ctx.Method {
 				methodHit = true
+				ctx.Params = extractParams(trieNode.path, path, ctx.Params[:0])
+
+				// 路由级别的请求体限制优先
+				if router.MaxBodyBytes > 0 {
+					maxBodyBytes = router.MaxBodyBytes
+				}
+				if router.MaxMultipartMemory > 0 {
+					ctx.maxMultipartMemory = router.MaxMultipartMemory
+				}
 			}
 		}
 	}
 
-	// 注册日志中间件
-	ctx.handlers = append(ctx.handlers, dog.logReq)
-
-	if matched {
-		if methodHit {
+	if matched && methodHit {
+		if ctx.limitBody(maxBodyBytes) {
 			ctx.Next()
-			return
-		} else {
-			ctx.Data(http.StatusMethodNotAllowed, nil)
-			ctx.Abort()
-			return
 		}
+	} else if matched {
+		ctx.AbortWithStatus(http.StatusMethodNotAllowed)
 	} else {
-		ctx.Status(http.StatusNotFound)
-		ctx.Abort()
-		return
+		ctx.AbortWithStatus(http.StatusNotFound)
 	}
+
+	// 引擎收尾处理，即使处理链被中止也会执行
+	dog.logReq(ctx)
 }
 
 func init() {
 	clearTerminal()
-	fmt.Println(
+	fmt.Print(
 		"\u001B[36m" +
 			"\t _____          _  _  _          _____\n" +
 			"\t|  __ \\        | |(_)| |        |  __ \\\n" +
@@ -159,6 +241,10 @@ func (dog *Dog) SetLogPath(logPath string) {
 // 打印请求日志
 func (dog *Dog) logReq(ctx *Context) {
 	msg := fmt.Sprintf("%3d %-8s %s", ctx.Code, ctx.Method, ctx.Path)
+	for _, err := range ctx.Errors {
+		msg += " | " + err.Error()
+	}
+
 	if ctx.Code == http.StatusOK {
 		dog.logger.Info(msg)
 	} else {
@@ -1,7 +1,93 @@
 package PoliteDog
 
-import "testing"
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
 
 func TestDog_RegisterRouters(t *testing.T) {
 
 }
+
+func TestDog_Abort(t *testing.T) {
+	dog := NewDog()
+	router := NewRouter()
+	called := false
+	router.GET("/secret", func(ctx *Context) {
+		called = true
+	})
+	router.PreHandle(func(ctx *Context) {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		if !ctx.IsAborted() {
+			t.Error("IsAborted() = false after AbortWithStatus")
+		}
+	})
+	router.PostHandle(func(ctx *Context) {
+		called = true
+	})
+	dog.RegisterRouters(router)
+
+	w := httptest.NewRecorder()
+	dog.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/secret", nil))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if called {
+		t.Error("handlers after Abort were executed")
+	}
+}
+
+func TestDog_RecoveryNonErrorPanic(t *testing.T) {
+	dog := NewDog()
+	router := NewRouter()
+	router.GET("/missing", func(ctx *Context) {
+		_ = ctx.MustGet("user")
+	})
+	dog.RegisterRouters(router)
+
+	w := httptest.NewRecorder()
+	dog.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestDog_BindURIAndHeader(t *testing.T) {
+	var uri struct {
+		ID   int    `uri:"id" binding:"required,gt=0"`
+		File string `uri:"filepath"`
+	}
+	var header struct {
+		Tenant string `header:"X-Tenant-Id" binding:"required"`
+	}
+
+	var bindErr error
+	requestReplaced := false
+	dog := NewDog()
+	group := NewRouterGroup("admin")
+	group.GET("/user/:id/files/*filepath", func(ctx *Context) {
+		r := ctx.r
+		if bindErr = ctx.BindURI(&uri); bindErr == nil {
+			bindErr = ctx.BindHeader(&header)
+		}
+		requestReplaced = ctx.r != r
+	})
+	dog.RegisterRouterGroup(group)
+
+	r := httptest.NewRequest(http.MethodGet, "/admin/user/42/files/docs/a.txt", nil)
+	r.Header.Set("x-tenant-id", "acme")
+	dog.ServeHTTP(httptest.NewRecorder(), r)
+
+	if bindErr != nil {
+		t.Fatal(bindErr)
+	}
+	if uri.ID != 42 || uri.File != "docs/a.txt" || header.Tenant != "acme" {
+		t.Errorf("got %+v %+v", uri, header)
+	}
+	if requestReplaced {
+		t.Error("BindURI replaced the request of the Context")
+	}
+}
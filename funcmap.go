@@ -0,0 +1,202 @@
+package PoliteDog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// CSRFFieldName csrfField生成的表单字段名称
+const CSRFFieldName = "_csrf"
+
+// 静态资源，asset根据文件内容生成带指纹的URL
+type assetManifest struct {
+	mu     sync.RWMutex
+	prefix string
+	fsys   fs.FS
+	hashes map[string]string
+}
+
+// SetAssets 设置asset模板函数使用的静态资源，prefix为StaticFS注册的路由前缀，
+// 如SetAssets("/static", fsys)后{{asset "css/site.css"}}输出/static/css/site.css?v=指纹
+func (dog *Dog) SetAssets(prefix string, fsys fs.FS) {
+	dog.assets.mu.Lock()
+	dog.assets.prefix = prefix
+	dog.assets.fsys = fsys
+	dog.assets.hashes = make(map[string]string)
+	dog.assets.mu.Unlock()
+}
+
+// FuncMap 获取模板使用的函数，内置函数会被TmplFuncMap中的同名函数覆盖
+func (dog *Dog) FuncMap() template.FuncMap {
+	funcMap := template.FuncMap{
+		"date":      formatDate,
+		"safeHTML":  safeHTML,
+		"safeURL":   safeURL,
+		"json":      toJSON,
+		"default":   defaultValue,
+		"dict":      dict,
+		"list":      list,
+		"pluralize": pluralize,
+		"truncate":  truncate,
+		"asset":     dog.asset,
+		"csrfField": csrfField,
+	}
+	for name, fn := range dog.TmplFuncMap {
+		funcMap[name] = fn
+	}
+
+	return funcMap
+}
+
+// 资源的指纹URL，调试模式下每次重新计算指纹
+func (dog *Dog) asset(name string) (string, error) {
+	name = strings.TrimPrefix(name, "/")
+
+	dog.assets.mu.RLock()
+	prefix, fsys := dog.assets.prefix, dog.assets.fsys
+	hash, ok := dog.assets.hashes[name]
+	dog.assets.mu.RUnlock()
+
+	if fsys == nil {
+		return "", errors.New("PoliteDog: asset used without SetAssets")
+	}
+
+	if !ok || dog.IsDebugging() {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return "", fmt.Errorf("PoliteDog: asset %q: %w", name, err)
+		}
+		hash = md5Encode(data)[:8]
+
+		dog.assets.mu.Lock()
+		dog.assets.hashes[name] = hash
+		dog.assets.mu.Unlock()
+	}
+
+	return path.Join("/", prefix, name) + "?v=" + hash, nil
+}
+
+// 格式化时间，支持time.Time、*time.Time和Unix秒，零值输出空字符串，如{{.CreatedAt | date "2006-01-02"}}
+func formatDate(layout string, v any) (string, error) {
+	var t time.Time
+	switch v := v.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v != nil {
+			t = *v
+		}
+	case int64:
+		t = time.Unix(v, 0)
+	case int:
+		t = time.Unix(int64(v), 0)
+	default:
+		return "", fmt.Errorf("date: unsupported type %T", v)
+	}
+
+	if t.IsZero() {
+		return "", nil
+	}
+	return t.Format(layout), nil
+}
+
+// 不转义输出HTML，只能用于可信内容
+func safeHTML(s string) template.HTML {
+	return template.HTML(s)
+}
+
+// 不过滤输出URL，只能用于可信内容
+func safeURL(s string) template.URL {
+	return template.URL(s)
+}
+
+// 序列化为JSON，可直接用于<script>中
+func toJSON(v any) (template.JS, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return template.JS(data), nil
+}
+
+// v为零值时使用def，如{{.Name | default "匿名"}}
+func defaultValue(def any, v any) any {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.IsZero() {
+		return def
+	}
+	if (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map) && rv.Len() == 0 {
+		return def
+	}
+
+	return v
+}
+
+// 由键值对创建map，用于向template传递多个参数，如{{template "item" dict "Name" .Name "Count" 3}}
+func dict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, errors.New("dict: odd number of arguments")
+	}
+
+	m := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key %v is not a string", pairs[i])
+		}
+		m[key] = pairs[i+1]
+	}
+
+	return m, nil
+}
+
+// 由参数创建切片，如{{range list "a" "b"}}，不使用slice命名以免覆盖模板内置的slice函数
+func list(items ...any) []any {
+	return items
+}
+
+// 数量为1时使用单数形式，如{{.Count | pluralize "item" "items"}}
+func pluralize(singular string, plural string, count any) (string, error) {
+	rv := reflect.ValueOf(count)
+	var n float64
+	switch {
+	case rv.CanInt():
+		n = float64(rv.Int())
+	case rv.CanUint():
+		n = float64(rv.Uint())
+	case rv.CanFloat():
+		n = rv.Float()
+	default:
+		return "", fmt.Errorf("pluralize: unsupported type %T", count)
+	}
+
+	if n == 1 {
+		return singular, nil
+	}
+	return plural, nil
+}
+
+// 截取前length个字符，超出时以省略号结尾，如{{.Body | truncate 100}}
+func truncate(length int, s string) string {
+	if length < 0 || utf8.RuneCountInString(s) <= length {
+		return s
+	}
+
+	runes := []rune(s)
+	return string(runes[:length]) + "…"
+}
+
+// CSRF令牌的隐藏表单字段，如{{csrfField .CSRFToken}}
+func csrfField(token string) template.HTML {
+	return template.HTML(`<input type="hidden" name="` + CSRFFieldName + `" value="` + template.HTMLEscapeString(token) + `">`)
+}
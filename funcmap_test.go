@@ -0,0 +1,63 @@
+package PoliteDog
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestDog_FuncMap(t *testing.T) {
+	dog := NewDog()
+	dog.SetAssets("/static", fstest.MapFS{"css/site.css": &fstest.MapFile{Data: []byte("body{}")}})
+	dog.SetFuncMap(template.FuncMap{"truncate": func(n int, s string) string { return "custom" }})
+
+	data := map[string]any{
+		"Time":  time.Date(2024, 2, 17, 11, 30, 0, 0, time.UTC),
+		"Zero":  time.Time{},
+		"Items": []string{},
+		"Token": `a"b`,
+	}
+	cases := map[string]string{
+		`{{.Time | date "2006-01-02"}}`:                                     "2024-02-17",
+		`[{{.Zero | date "2006-01-02"}}]`:                                   "[]",
+		`{{"<b>x</b>" | safeHTML}}`:                                         "<b>x</b>",
+		`<a href="{{"javascript:x" | safeURL}}">`:                           `<a href="javascript:x">`,
+		`<script>var v = {{json .Items}};</script>`:                         `<script>var v = [];</script>`,
+		`{{.Items | default "none"}} {{"x" | default "y"}}`:                 "none x",
+		`{{with dict "A" 1 "B" "b"}}{{.A}}{{.B}}{{end}}`:                    "1b",
+		`{{range list 1 2 3}}{{.}}{{end}}`:                                  "123",
+		`{{slice "PoliteDog" 0 6}}`:                                         "Polite",
+		`{{1 | pluralize "item" "items"}} {{2 | pluralize "item" "items"}}`: "item items",
+		`{{"long text" | truncate 4}}`:                                      "custom",
+		`{{asset "/css/site.css"}}`:                                         "/static/css/site.css?v=" + md5Encode([]byte("body{}"))[:8],
+		`{{csrfField .Token}}`:                                              `<input type="hidden" name="_csrf" value="a&#34;b">`,
+	}
+
+	for text, want := range cases {
+		tmpl, err := template.New("").Funcs(dog.FuncMap()).Parse(text)
+		if err != nil {
+			t.Fatalf("%s: %v", text, err)
+		}
+
+		var sb strings.Builder
+		if err = tmpl.Execute(&sb, data); err != nil {
+			t.Errorf("%s: %v", text, err)
+			continue
+		}
+		if sb.String() != want {
+			t.Errorf("%s: got %q, want %q", text, sb.String(), want)
+		}
+	}
+
+	if got := truncate(4, "你好世界啊"); got != "你好世界…" {
+		t.Errorf("truncate got %q", got)
+	}
+	if _, err := dict("A"); err == nil {
+		t.Error("dict with odd arguments returned nil error")
+	}
+	if _, err := dog.asset("missing.css"); err == nil {
+		t.Error("asset for missing file returned nil error")
+	}
+}
@@ -0,0 +1,44 @@
+package PoliteDog
+
+// Key 类型化的上下文密钥，以指针地址区分，不同中间件包即使同名也不会冲突
+type Key[T any] struct {
+	name string
+}
+
+// NewKey 创建类型化的上下文密钥
+func NewKey[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+// String 返回密钥名称
+func (k *Key[T]) String() string {
+	return k.name
+}
+
+// Set 设置类型化的密钥
+func Set[T any](c *Context, key *Key[T], value T) {
+	c.checkReleased()
+	c.m.Lock()
+	if c.typedKeys == nil {
+		c.typedKeys = make(map[any]any)
+	}
+	c.typedKeys[key] = value
+	c.m.Unlock()
+}
+
+// Get 获取类型化的密钥，不存在时返回零值和false
+func Get[T any](c *Context, key *Key[T]) (T, bool) {
+	c.checkReleased()
+	c.m.RLock()
+	keyValue, ok := c.typedKeys[key]
+	c.m.RUnlock()
+
+	if !ok {
+		var zero T
+		return zero, false
+	}
+
+	// 存入的值为nil接口时断言会失败，此时返回零值
+	v, _ := keyValue.(T)
+	return v, true
+}
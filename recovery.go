@@ -42,15 +42,21 @@ func (der *DogError) ExecuteError() {
 // Recovery 异常统一处理
 func Recovery(ctx *Context) {
 	defer func() {
-		err := recover()
-		if err != nil {
+		v := recover()
+		if v != nil {
+			// MustGet等以字符串panic，统一包装为error
+			err, ok := v.(error)
+			if !ok {
+				err = fmt.Errorf("%v", v)
+			}
+
 			var der *DogError
-			if errors.As(err.(error), &der) {
+			if errors.As(err, &der) {
 				der.fn(der)
 			}
 
 			ctx.e.logger.Error(getStackFrame(err))
-			ctx.Data(http.StatusInternalServerError, nil)
+			ctx.AbortWithStatus(http.StatusInternalServerError)
 		}
 	}()
 
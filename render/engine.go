@@ -0,0 +1,143 @@
+package render
+
+import (
+	"errors"
+	"html/template"
+	"io"
+	"io/fs"
+	"sync"
+	texttemplate "text/template"
+)
+
+// ErrNoTemplate 未加载模板时渲染
+var ErrNoTemplate = errors.New("render: no template loaded")
+
+// HTMLEngine HTML模板引擎，Dog通过它加载和渲染模板，可替换为Markdown、预编译模板等自定义实现
+type HTMLEngine interface {
+	// Load 加载或重新加载模板，失败时应保留原有模板
+	Load() error
+	// Render 使用名为name的模板渲染data，可能被并发调用
+	Render(w io.Writer, name string, data any) error
+}
+
+// HTMLTemplateEngine 基于html/template的模板引擎
+type HTMLTemplateEngine struct {
+	Name     string           // 根模板名称，AddTemplateSet中为布局或页面的文件名
+	FS       fs.FS            // 模板所在的文件系统，为nil时从磁盘读取
+	Patterns []string         // 模板文件的glob，为空时Load不做任何事
+	FuncMap  template.FuncMap // 模板函数
+
+	mu   sync.RWMutex
+	tmpl *template.Template
+}
+
+// NewHTMLTemplateEngine 使用已解析的模板创建引擎
+func NewHTMLTemplateEngine(tmpl *template.Template) *HTMLTemplateEngine {
+	return &HTMLTemplateEngine{tmpl: tmpl}
+}
+
+func (e *HTMLTemplateEngine) Load() error {
+	if len(e.Patterns) == 0 {
+		return nil
+	}
+
+	tmpl, err := parseTemplates(template.New(e.Name).Funcs(e.FuncMap), e.FS, e.Patterns)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.tmpl = tmpl
+	e.mu.Unlock()
+
+	return nil
+}
+
+func (e *HTMLTemplateEngine) Render(w io.Writer, name string, data any) error {
+	tmpl := e.Template()
+	if tmpl == nil {
+		return ErrNoTemplate
+	}
+
+	return tmpl.ExecuteTemplate(w, name, data)
+}
+
+// Template 获取最近一次加载的模板
+func (e *HTMLTemplateEngine) Template() *template.Template {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.tmpl
+}
+
+// TextTemplateEngine 基于text/template的模板引擎，输出不做HTML转义
+type TextTemplateEngine struct {
+	Name     string               // 根模板名称
+	FS       fs.FS                // 模板所在的文件系统，为nil时从磁盘读取
+	Patterns []string             // 模板文件的glob，为空时Load不做任何事
+	FuncMap  texttemplate.FuncMap // 模板函数
+
+	mu   sync.RWMutex
+	tmpl *texttemplate.Template
+}
+
+// NewTextTemplateEngine 使用已解析的模板创建引擎
+func NewTextTemplateEngine(tmpl *texttemplate.Template) *TextTemplateEngine {
+	return &TextTemplateEngine{tmpl: tmpl}
+}
+
+func (e *TextTemplateEngine) Load() error {
+	if len(e.Patterns) == 0 {
+		return nil
+	}
+
+	tmpl, err := parseTemplates(texttemplate.New(e.Name).Funcs(e.FuncMap), e.FS, e.Patterns)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.tmpl = tmpl
+	e.mu.Unlock()
+
+	return nil
+}
+
+func (e *TextTemplateEngine) Render(w io.Writer, name string, data any) error {
+	tmpl := e.Template()
+	if tmpl == nil {
+		return ErrNoTemplate
+	}
+
+	return tmpl.ExecuteTemplate(w, name, data)
+}
+
+// Template 获取最近一次加载的模板
+func (e *TextTemplateEngine) Template() *texttemplate.Template {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.tmpl
+}
+
+// html/template和text/template共同的解析方法
+type templateParser[T any] interface {
+	ParseFS(fsys fs.FS, patterns ...string) (T, error)
+	ParseGlob(pattern string) (T, error)
+}
+
+// 解析模板文件，fsys为nil时从磁盘读取
+func parseTemplates[T templateParser[T]](tmpl T, fsys fs.FS, patterns []string) (T, error) {
+	if fsys != nil {
+		return tmpl.ParseFS(fsys, patterns...)
+	}
+
+	var err error
+	for _, pattern := range patterns {
+		if tmpl, err = tmpl.ParseGlob(pattern); err != nil {
+			return tmpl, err
+		}
+	}
+
+	return tmpl, nil
+}
@@ -3,21 +3,39 @@ package render
 import (
 	"github.com/fangnan700/PoliteDog/internal/bytesconv"
 	"html/template"
+	"io/fs"
 	"net/http"
 )
 
 type HTMLRender struct {
 	Name     string
 	Data     any
+	Engine   HTMLEngine // 渲染使用的模板引擎，为nil时使用Template
 	Template *template.Template
 	IsTmpl   bool
 }
 
+// NewHTMLRenderFS 从fs.FS中解析模板，可用于embed.FS，patterns的写法与fs.Glob相同
+func NewHTMLRenderFS(fsys fs.FS, funcMap template.FuncMap, patterns ...string) (*HTMLRender, error) {
+	engine := &HTMLTemplateEngine{FS: fsys, Patterns: patterns, FuncMap: funcMap}
+	if err := engine.Load(); err != nil {
+		return nil, err
+	}
+
+	return &HTMLRender{Engine: engine, Template: engine.Template(), IsTmpl: true}, nil
+}
+
 func (h *HTMLRender) Render(w http.ResponseWriter) error {
 	h.WriteContentType(w)
 
 	if h.IsTmpl {
 		// 使用模板
+		if h.Engine != nil {
+			return h.Engine.Render(w, h.Name, h.Data)
+		}
+		if h.Template == nil {
+			return ErrNoTemplate
+		}
 		err := h.Template.ExecuteTemplate(w, h.Name, h.Data)
 		if err != nil {
 			return err
@@ -1,18 +1,27 @@
 package render
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fangnan700/PoliteDog/codec"
+	"github.com/fangnan700/PoliteDog/internal/bytesconv"
 	"net/http"
+	"regexp"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 type JSONRender struct {
-	Data any
+	Data  any
+	Codec codec.Codec // 为nil时使用codec.JSON
 }
 
 func (j *JSONRender) Render(w http.ResponseWriter) error {
 	j.WriteContentType(w)
 
-	jd, err := json.Marshal(j.Data)
+	jd, err := codecOrDefault(j.Codec, codec.JSON).Marshal(j.Data)
 	if err != nil {
 		return err
 	}
@@ -24,3 +33,177 @@ func (j *JSONRender) Render(w http.ResponseWriter) error {
 func (j *JSONRender) WriteContentType(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 }
+
+// IndentedJSONRender 缩进格式的JSON，便于调试时阅读
+type IndentedJSONRender struct {
+	Data  any
+	Codec codec.Codec // 为nil时使用codec.JSON
+}
+
+func (j *IndentedJSONRender) Render(w http.ResponseWriter) error {
+	j.WriteContentType(w)
+
+	jd, err := codecOrDefault(j.Codec, codec.JSON).Marshal(j.Data)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err = json.Indent(&buf, jd, "", "    "); err != nil {
+		return err
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func (j *IndentedJSONRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+}
+
+// DefaultSecureJSONPrefix SecureJSON默认的防劫持前缀
+const DefaultSecureJSONPrefix = "while(1);"
+
+// SecureJSONRender 在JSON前加上前缀，防止响应被<script>标签引用后劫持，客户端需要去除前缀后再解析
+type SecureJSONRender struct {
+	Prefix string // 为空时使用DefaultSecureJSONPrefix
+	Data   any
+	Codec  codec.Codec // 为nil时使用codec.JSON
+}
+
+func (s *SecureJSONRender) Render(w http.ResponseWriter) error {
+	s.WriteContentType(w)
+
+	jd, err := codecOrDefault(s.Codec, codec.JSON).Marshal(s.Data)
+	if err != nil {
+		return err
+	}
+
+	prefix := s.Prefix
+	if prefix == "" {
+		prefix = DefaultSecureJSONPrefix
+	}
+
+	if _, err = w.Write(bytesconv.StringToBytes(prefix)); err != nil {
+		return err
+	}
+
+	_, err = w.Write(jd)
+	return err
+}
+
+func (s *SecureJSONRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+}
+
+// ErrInvalidCallback JSONP的回调函数名不合法
+var ErrInvalidCallback = errors.New("render: invalid JSONP callback")
+
+// 回调函数名只允许以点连接的JavaScript标识符，如jQuery123.cb
+var callbackPattern = regexp.MustCompile(`^[A-Za-z_$][0-9A-Za-z_$]*(\.[A-Za-z_$][0-9A-Za-z_$]*)*$`)
+
+// IsValidCallback 判断JSONP回调函数名是否合法
+func IsValidCallback(callback string) bool {
+	return len(callback) <= 128 && callbackPattern.MatchString(callback)
+}
+
+// JSONPRender 以回调函数包裹的JSON，回调为空时与JSONRender相同
+type JSONPRender struct {
+	Callback string
+	Data     any
+	Codec    codec.Codec // 为nil时使用codec.JSON
+}
+
+func (j *JSONPRender) Render(w http.ResponseWriter) error {
+	if j.Callback != "" && !IsValidCallback(j.Callback) {
+		return ErrInvalidCallback
+	}
+	j.WriteContentType(w)
+
+	jd, err := codecOrDefault(j.Codec, codec.JSON).Marshal(j.Data)
+	if err != nil {
+		return err
+	}
+
+	if j.Callback == "" {
+		_, err = w.Write(jd)
+		return err
+	}
+
+	// 开头的空注释用于防范Rosetta Flash等利用回调名构造内容的攻击
+	var buf bytes.Buffer
+	buf.WriteString("/**/")
+	buf.WriteString(j.Callback)
+	buf.WriteByte('(')
+	buf.Write(jd)
+	buf.WriteString(");")
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func (j *JSONPRender) WriteContentType(w http.ResponseWriter) {
+	if j.Callback == "" {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+}
+
+// AsciiJSONRender 将非ASCII字符转义为\uXXXX的JSON
+type AsciiJSONRender struct {
+	Data  any
+	Codec codec.Codec // 为nil时使用codec.JSON
+}
+
+func (a *AsciiJSONRender) Render(w http.ResponseWriter) error {
+	a.WriteContentType(w)
+
+	jd, err := codecOrDefault(a.Codec, codec.JSON).Marshal(a.Data)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, r := range bytesconv.BytesToString(jd) {
+		switch {
+		case r < utf8.RuneSelf:
+			buf.WriteByte(byte(r))
+		case r > 0xffff:
+			// 超出基本平面的字符使用代理对
+			r1, r2 := utf16.EncodeRune(r)
+			fmt.Fprintf(&buf, `\u%04x\u%04x`, r1, r2)
+		default:
+			fmt.Fprintf(&buf, `\u%04x`, r)
+		}
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func (a *AsciiJSONRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+}
+
+// PureJSONRender 不转义<、>、&等HTML字符的JSON
+type PureJSONRender struct {
+	Data  any
+	Codec codec.Codec // 为nil时使用codec.JSON
+}
+
+func (p *PureJSONRender) Render(w http.ResponseWriter) error {
+	p.WriteContentType(w)
+
+	encoder := codecOrDefault(p.Codec, codec.JSON).NewEncoder(w)
+	if e, ok := encoder.(interface{ SetEscapeHTML(on bool) }); ok {
+		e.SetEscapeHTML(false)
+	}
+
+	return encoder.Encode(p.Data)
+}
+
+func (p *PureJSONRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+}
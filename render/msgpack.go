@@ -0,0 +1,27 @@
+package render
+
+import (
+	"github.com/fangnan700/PoliteDog/codec"
+	"net/http"
+)
+
+type MsgPackRender struct {
+	Data  any
+	Codec codec.Codec // 为nil时使用codec.MsgPack
+}
+
+func (m *MsgPackRender) Render(w http.ResponseWriter) error {
+	m.WriteContentType(w)
+
+	data, err := codecOrDefault(m.Codec, codec.MsgPack).Marshal(m.Data)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+func (m *MsgPackRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/msgpack")
+}
@@ -0,0 +1,36 @@
+package render
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+)
+
+type ReaderRender struct {
+	ContentType   string
+	ContentLength int64 // 小于0时不设置Content-Length，使用分块传输
+	Reader        io.Reader
+	Headers       map[string]string
+}
+
+func (r *ReaderRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+
+	_, err := io.Copy(w, r.Reader)
+	return err
+}
+
+func (r *ReaderRender) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if r.ContentType != "" {
+		header.Set("Content-Type", r.ContentType)
+	}
+	if r.ContentLength >= 0 {
+		header.Set("Content-Length", strconv.FormatInt(r.ContentLength, 10))
+	}
+	for key, value := range r.Headers {
+		if header.Get(key) == "" {
+			header.Set(key, value)
+		}
+	}
+}
@@ -1,8 +1,20 @@
 package render
 
-import "net/http"
+import (
+	"github.com/fangnan700/PoliteDog/codec"
+	"net/http"
+)
 
 type Render interface {
 	Render(w http.ResponseWriter) error
 	WriteContentType(w http.ResponseWriter)
 }
+
+// 未指定编解码器时使用默认实现
+func codecOrDefault(c codec.Codec, def codec.Codec) codec.Codec {
+	if c == nil {
+		return def
+	}
+
+	return c
+}
@@ -0,0 +1,27 @@
+package render
+
+import (
+	"github.com/fangnan700/PoliteDog/codec"
+	"net/http"
+)
+
+type TOMLRender struct {
+	Data  any
+	Codec codec.Codec // 为nil时使用codec.TOML
+}
+
+func (t *TOMLRender) Render(w http.ResponseWriter) error {
+	t.WriteContentType(w)
+
+	data, err := codecOrDefault(t.Codec, codec.TOML).Marshal(t.Data)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+func (t *TOMLRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/toml; charset=utf-8")
+}
@@ -1,18 +1,19 @@
 package render
 
 import (
-	"encoding/xml"
+	"github.com/fangnan700/PoliteDog/codec"
 	"net/http"
 )
 
 type XMLRender struct {
-	Data any
+	Data  any
+	Codec codec.Codec // 为nil时使用codec.XML
 }
 
 func (x *XMLRender) Render(w http.ResponseWriter) error {
 	x.WriteContentType(w)
 
-	err := xml.NewEncoder(w).Encode(x.Data)
+	err := codecOrDefault(x.Codec, codec.XML).NewEncoder(w).Encode(x.Data)
 	return err
 }
 
@@ -0,0 +1,27 @@
+package render
+
+import (
+	"github.com/fangnan700/PoliteDog/codec"
+	"net/http"
+)
+
+type YAMLRender struct {
+	Data  any
+	Codec codec.Codec // 为nil时使用codec.YAML
+}
+
+func (y *YAMLRender) Render(w http.ResponseWriter) error {
+	y.WriteContentType(w)
+
+	data, err := codecOrDefault(y.Codec, codec.YAML).Marshal(y.Data)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+func (y *YAMLRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+}
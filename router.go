@@ -1,6 +1,12 @@
 package PoliteDog
 
-import "net/http"
+import (
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
 
 /*
 Router 路由
@@ -10,6 +16,10 @@ type Router struct {
 	HandlerMap   map[string]HandlerFuc
 	PreHandlers  []HandlerFuc
 	PostHandlers []HandlerFuc
+
+	// 请求体限制，为0时使用引擎的配置
+	MaxMultipartMemory int64
+	MaxBodyBytes       int64
 }
 
 func NewRouter() *Router {
@@ -63,6 +73,16 @@ func (r *Router) DELETE(pattern string, handler HandlerFuc) {
 	r.handle(http.MethodDelete, pattern, handler)
 }
 
+// StaticFS 在prefix下提供fsys中的静态文件，可用于embed.FS，没有index.html的目录返回404
+func (r *Router) StaticFS(prefix string, fsys fs.FS) {
+	r.GET(staticPattern(prefix), staticHandler(fsys))
+}
+
+// Static 在prefix下提供磁盘目录root中的静态文件
+func (r *Router) Static(prefix string, root string) {
+	r.StaticFS(prefix, os.DirFS(root))
+}
+
 /*
 RouterGroup 路由组
 */
@@ -117,3 +137,49 @@ func (rg *RouterGroup) PUT(pattern string, handler HandlerFuc) {
 func (rg *RouterGroup) DELETE(pattern string, handler HandlerFuc) {
 	rg.handle(http.MethodDelete, pattern, handler)
 }
+
+// StaticFS 在prefix下提供fsys中的静态文件，可用于embed.FS，没有index.html的目录返回404
+func (rg *RouterGroup) StaticFS(prefix string, fsys fs.FS) {
+	rg.GET(staticPattern(prefix), staticHandler(fsys))
+}
+
+// Static 在prefix下提供磁盘目录root中的静态文件
+func (rg *RouterGroup) Static(prefix string, root string) {
+	rg.StaticFS(prefix, os.DirFS(root))
+}
+
+// 静态文件路由，*filepath匹配prefix之后的全部路径
+func staticPattern(prefix string) string {
+	return strings.TrimSuffix(prefix, "/") + "/*filepath"
+}
+
+// 使用http.FileServer响应静态文件，不列出目录内容
+func staticHandler(fsys fs.FS) HandlerFuc {
+	fileServer := http.FileServer(http.FS(fsys))
+
+	return func(ctx *Context) {
+		file := ctx.Param("filepath")
+		name := strings.TrimPrefix(path.Clean("/"+file), "/")
+		if name == "" {
+			name = "."
+		}
+
+		info, err := fs.Stat(fsys, name)
+		if err == nil && info.IsDir() {
+			_, err = fs.Stat(fsys, path.Join(name, "index.html"))
+		}
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		// 复制请求，使文件服务器以prefix之后的路径查找文件
+		r := ctx.r.Clone(ctx.r.Context())
+		r.URL.Path = "/" + file
+		r.URL.RawPath = ""
+
+		sw := &statusWriter{ResponseWriter: ctx.w}
+		fileServer.ServeHTTP(sw, r)
+		ctx.Code = sw.status
+	}
+}
@@ -2,7 +2,10 @@ package PoliteDog
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"testing/fstest"
 )
 
 func TestRouter(t *testing.T) {
@@ -15,3 +18,43 @@ func TestRouter(t *testing.T) {
 	fmt.Printf("%+v\n", g.Routers.RouterTrie.next.children[0].children[0])
 	fmt.Printf("%+v\n", g.Routers.RouterTrie.next.Search("/admin/login/1"))
 }
+
+func TestRouter_StaticFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"css/site.css":    &fstest.MapFile{Data: []byte("body{}")},
+		"docs/index.html": &fstest.MapFile{Data: []byte("<h1>docs</h1>")},
+		"empty/a.txt":     &fstest.MapFile{Data: []byte("a")},
+	}
+
+	r := NewRouter()
+	r.StaticFS("/assets", fsys)
+	g := NewRouterGroup("admin")
+	g.StaticFS("/static/", fsys)
+
+	dog := NewDog()
+	dog.RegisterRouters(r)
+	dog.RegisterRouterGroup(g)
+
+	cases := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/assets/css/site.css", http.StatusOK, "body{}"},
+		{"/admin/static/css/site.css", http.StatusOK, "body{}"},
+		{"/assets/docs/", http.StatusOK, "<h1>docs</h1>"},
+		{"/assets/empty/", http.StatusNotFound, ""},
+		{"/assets/missing.css", http.StatusNotFound, ""},
+		{"/assets/../router.go", http.StatusNotFound, ""},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		dog.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if w.Code != tc.code {
+			t.Errorf("%s: code = %d, want %d", tc.path, w.Code, tc.code)
+		}
+		if tc.body != "" && w.Body.String() != tc.body {
+			t.Errorf("%s: body = %q, want %q", tc.path, w.Body.String(), tc.body)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package PoliteDog
+
+import (
+	"github.com/fangnan700/PoliteDog/binding"
+	"io"
+)
+
+// BindStream 逐个绑定application/x-ndjson或顶层为JSON数组的请求体，每个元素解码、校验后交给fn处理，
+// 请求体不会整体读入内存。maxItems<=0时不限制元素数量，超出时响应413。
+// 绑定失败时与Bind一样响应对应的状态码并中止处理链，fn返回的错误会停止绑定并原样返回
+func BindStream[T any](c *Context, maxItems int, fn func(item *T) error) error {
+	c.checkReleased()
+
+	dec, err := binding.NewStreamDecoder(c.r.Body, c.r.Header.Get("Content-Type"), c.e.Codec(binding.MIMEJSON), maxItems)
+	if err != nil {
+		return c.abortBind(err)
+	}
+	dec.DisallowUnknownFields = c.DisallowUnknownFields
+
+	for {
+		item := new(T)
+		if err = dec.Next(item); err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return c.abortBind(c.checkBodyError(err))
+		}
+
+		if err = fn(item); err != nil {
+			return err
+		}
+	}
+}
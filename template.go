@@ -0,0 +1,270 @@
+package PoliteDog
+
+import (
+	"fmt"
+	"github.com/fangnan700/PoliteDog/render"
+	"html/template"
+	"io/fs"
+	"maps"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// TemplateSet 一组独立解析的模板，由布局、页面和局部模板组成，
+// 不同模板组中同名的define互不影响
+type TemplateSet struct {
+	FS       fs.FS    // 模板所在的文件系统，如embed.FS，为nil时从磁盘读取
+	Layout   string   // 布局文件，为空时直接渲染页面
+	Page     string   // 页面文件，通常在其中define布局引用的块
+	Partials []string // 局部模板，支持glob
+}
+
+// 加载后缓存的模板引擎，记录来源文件以便调试模式下检测变化
+type cachedTemplate struct {
+	engine   render.HTMLEngine
+	entry    string   // 渲染时执行的模板名称，为空时使用调用方指定的名称
+	fsys     fs.FS    // 来源文件所在的文件系统，为nil时为磁盘
+	patterns []string // 来源文件的glob，为空时不会重新加载
+	modTimes map[string]time.Time
+}
+
+// 模板注册表，模板在注册时解析一次并缓存，调试模式下来源文件变化时重新解析
+type templateRegistry struct {
+	mu    sync.RWMutex
+	flat  *cachedTemplate            // LoadTemplate、SetHTMLEngine设置的模板
+	sets  map[string]*cachedTemplate // AddTemplateSet注册的模板组
+	globs map[string]*cachedTemplate // HTMLTemplateGlob使用的模板
+}
+
+// 加载模板并记录来源文件的修改时间，内置引擎的来源文件从其配置中获取
+func newCachedTemplate(entry string, engine render.HTMLEngine) (*cachedTemplate, error) {
+	ct := &cachedTemplate{engine: engine, entry: entry}
+	switch e := engine.(type) {
+	case *render.HTMLTemplateEngine:
+		ct.fsys, ct.patterns = e.FS, e.Patterns
+	case *render.TextTemplateEngine:
+		ct.fsys, ct.patterns = e.FS, e.Patterns
+	}
+
+	if err := ct.reload(); err != nil {
+		return nil, err
+	}
+
+	return ct, nil
+}
+
+func (ct *cachedTemplate) reload() error {
+	modTimes, err := templateModTimes(ct.fsys, ct.patterns)
+	if err != nil {
+		return err
+	}
+
+	if err = ct.engine.Load(); err != nil {
+		return err
+	}
+
+	ct.modTimes = modTimes
+	return nil
+}
+
+// 来源文件有新增、删除或修改时重新加载，加载失败时保留原有模板，下次渲染时重试
+func (ct *cachedTemplate) refresh() error {
+	modTimes, err := templateModTimes(ct.fsys, ct.patterns)
+	if err == nil && maps.Equal(modTimes, ct.modTimes) {
+		return nil
+	}
+
+	return ct.reload()
+}
+
+// 创建使用内置函数和TmplFuncMap的html/template引擎
+func (dog *Dog) newTemplateEngine(name string, fsys fs.FS, patterns []string) *render.HTMLTemplateEngine {
+	return &render.HTMLTemplateEngine{Name: name, FS: fsys, Patterns: patterns, FuncMap: dog.FuncMap()}
+}
+
+// 获取glob匹配的所有文件的修改时间，embed.FS中文件的修改时间为零值，不会触发重新解析
+func templateModTimes(fsys fs.FS, patterns []string) (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time)
+	for _, pattern := range patterns {
+		var files []string
+		var err error
+		if fsys == nil {
+			files, err = filepath.Glob(pattern)
+		} else {
+			files, err = fs.Glob(fsys, pattern)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			var info fs.FileInfo
+			if fsys == nil {
+				info, err = os.Stat(file)
+			} else {
+				info, err = fs.Stat(fsys, file)
+			}
+			if err != nil {
+				return nil, err
+			}
+			modTimes[file] = info.ModTime()
+		}
+	}
+
+	return modTimes, nil
+}
+
+// LoadTemplate 加载模板，调试模式下模板文件变化后会在下次渲染前重新解析
+func (dog *Dog) LoadTemplate(pattern string) {
+	if err := dog.SetHTMLEngine(dog.newTemplateEngine("", nil, []string{pattern})); err != nil {
+		panic(err)
+	}
+}
+
+// LoadTemplateFS 从fs.FS中加载模板，可用于embed.FS，patterns的写法与fs.Glob相同
+func (dog *Dog) LoadTemplateFS(fsys fs.FS, patterns ...string) {
+	if err := dog.SetHTMLEngine(dog.newTemplateEngine("", fsys, patterns)); err != nil {
+		panic(err)
+	}
+}
+
+// SetHTMLEngine 加载并使用自定义模板引擎，ctx.HTMLTemplate未匹配到模板组时使用它渲染。
+// 内置的HTMLTemplateEngine和TextTemplateEngine在调试模式下会随模板文件变化重新加载
+func (dog *Dog) SetHTMLEngine(engine render.HTMLEngine) error {
+	ct, err := newCachedTemplate("", engine)
+	if err != nil {
+		return err
+	}
+
+	dog.templates.mu.Lock()
+	dog.templates.flat = ct
+	dog.HTMLEngine = engine
+	dog.templates.mu.Unlock()
+
+	return nil
+}
+
+// AddTemplateSet 注册模板组并立即解析，ctx.HTMLTemplate按名称选择模板组渲染，
+// 解析时使用TmplFuncMap，因此需要先调用SetFuncMap
+func (dog *Dog) AddTemplateSet(name string, set TemplateSet) error {
+	if set.Page == "" {
+		return fmt.Errorf("PoliteDog: template set %q without page", name)
+	}
+
+	// 有布局时从布局开始渲染
+	files := []string{set.Page}
+	if set.Layout != "" {
+		files = []string{set.Layout, set.Page}
+	}
+
+	// 磁盘和fs.FS中的模板都以文件名命名
+	entry := filepath.Base(files[0])
+	if set.FS != nil {
+		entry = path.Base(files[0])
+	}
+
+	ct, err := newCachedTemplate(entry, dog.newTemplateEngine(entry, set.FS, append(files, set.Partials...)))
+	if err != nil {
+		return err
+	}
+
+	dog.templates.mu.Lock()
+	if dog.templates.sets == nil {
+		dog.templates.sets = make(map[string]*cachedTemplate)
+	}
+	dog.templates.sets[name] = ct
+	dog.templates.mu.Unlock()
+
+	return nil
+}
+
+// 获取渲染name使用的模板引擎和入口名称，优先使用同名的模板组
+func (dog *Dog) lookupTemplate(name string) (render.HTMLEngine, string, error) {
+	if dog.IsDebugging() {
+		// 调试模式下检测文件变化，加写锁避免并发重新加载
+		dog.templates.mu.Lock()
+		defer dog.templates.mu.Unlock()
+
+		if set, ok := dog.templates.sets[name]; ok {
+			err := set.refresh()
+			return set.engine, set.entry, err
+		}
+		if flat := dog.templates.flat; flat != nil {
+			err := flat.refresh()
+			return dog.HTMLEngine, name, err
+		}
+		return dog.HTMLEngine, name, nil
+	}
+
+	dog.templates.mu.RLock()
+	defer dog.templates.mu.RUnlock()
+
+	if set, ok := dog.templates.sets[name]; ok {
+		return set.engine, set.entry, nil
+	}
+	return dog.HTMLEngine, name, nil
+}
+
+// 获取glob对应的模板引擎，首次使用时解析并缓存
+func (dog *Dog) globTemplate(pattern string) (render.HTMLEngine, error) {
+	dog.templates.mu.RLock()
+	ct, ok := dog.templates.globs[pattern]
+	dog.templates.mu.RUnlock()
+
+	if ok && !dog.IsDebugging() {
+		return ct.engine, nil
+	}
+
+	dog.templates.mu.Lock()
+	defer dog.templates.mu.Unlock()
+
+	// 加锁后重新获取，避免并发请求重复解析
+	if ct, ok = dog.templates.globs[pattern]; ok {
+		if !dog.IsDebugging() {
+			return ct.engine, nil
+		}
+		err := ct.refresh()
+		return ct.engine, err
+	}
+
+	ct, err := newCachedTemplate("", dog.newTemplateEngine("", nil, []string{pattern}))
+	if err != nil {
+		return nil, err
+	}
+
+	if dog.templates.globs == nil {
+		dog.templates.globs = make(map[string]*cachedTemplate)
+	}
+	dog.templates.globs[pattern] = ct
+
+	return ct.engine, nil
+}
+
+// 模板解析错误，调试模式下输出到浏览器和日志
+func (c *Context) templateError(err error) error {
+	if !c.e.IsDebugging() {
+		return err
+	}
+
+	c.e.logger.Error(fmt.Sprintf("template error: %v", err))
+	_ = c.renderStatus(http.StatusInternalServerError, &render.HTMLRender{
+		Data: fmt.Sprintf(templateErrorPage, template.HTMLEscapeString(err.Error())),
+	})
+	c.Abort()
+
+	return err
+}
+
+const templateErrorPage = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Template Error</title></head>
+<body>
+<h1>Template Error</h1>
+<pre>%s</pre>
+</body>
+</html>
+`
@@ -0,0 +1,241 @@
+package PoliteDog
+
+import (
+	"fmt"
+	"github.com/fangnan700/PoliteDog/render"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// 在临时目录中写入模板文件
+func writeTemplates(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func renderTemplate(t *testing.T, dog *Dog, name string, data any) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	ctx := &Context{e: dog, w: w, r: httptest.NewRequest(http.MethodGet, "/", nil)}
+	if err := ctx.HTMLTemplate(http.StatusOK, name, data); err != nil {
+		t.Fatalf("HTMLTemplate(%q) = %v", name, err)
+	}
+
+	return w.Body.String()
+}
+
+func TestDog_AddTemplateSet(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"layout.html":        `<title>{{block "title" .}}default{{end}}</title>{{template "nav" .}}{{block "content" .}}{{end}}`,
+		"pages/home.html":    `{{define "title"}}Home{{end}}{{define "content"}}hello {{upper .}}{{end}}`,
+		"pages/about.html":   `{{define "content"}}about{{end}}`,
+		"pages/plain.html":   `plain {{.}}`,
+		"partials/nav.html":  `{{define "nav"}}<nav/>{{end}}`,
+		"partials/foot.html": `{{define "foot"}}<footer/>{{end}}`,
+	})
+
+	dog := NewDog()
+	dog.SetFuncMap(template.FuncMap{"upper": strings.ToUpper})
+	partials := []string{filepath.Join(dir, "partials", "*.html")}
+	layout := filepath.Join(dir, "layout.html")
+
+	for name, page := range map[string]string{"home": "home.html", "about": "about.html"} {
+		err := dog.AddTemplateSet(name, TemplateSet{Layout: layout, Page: filepath.Join(dir, "pages", page), Partials: partials})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := dog.AddTemplateSet("plain", TemplateSet{Page: filepath.Join(dir, "pages", "plain.html")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 各模板组中同名的content互不影响
+	cases := map[string]string{
+		"home":  "<title>Home</title><nav/>hello DOG",
+		"about": "<title>default</title><nav/>about",
+		"plain": "plain dog",
+	}
+	for name, want := range cases {
+		if got := renderTemplate(t, dog, name, "dog"); got != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+
+	if err := dog.AddTemplateSet("broken", TemplateSet{Page: filepath.Join(dir, "missing.html")}); err == nil {
+		t.Error("AddTemplateSet with missing page returned nil error")
+	}
+}
+
+func TestContext_HTMLTemplateGlob(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{"index.html": `v1 {{.}}`})
+	dog := NewDog()
+	pattern := filepath.Join(dir, "*.html")
+
+	render := func() string {
+		w := httptest.NewRecorder()
+		ctx := &Context{e: dog, w: w, r: httptest.NewRequest(http.MethodGet, "/", nil)}
+		if err := ctx.HTMLTemplateGlob(http.StatusOK, "index.html", "dog", pattern); err != nil {
+			t.Fatal(err)
+		}
+		return w.Body.String()
+	}
+
+	if got := render(); got != "v1 dog" {
+		t.Fatalf("got %q", got)
+	}
+
+	// 模板只在首次使用时解析
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte(`v2`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := render(); got != "v1 dog" {
+		t.Errorf("got %q after change, want cached template", got)
+	}
+}
+
+func TestDog_TemplateHotReload(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{"index.html": `v1`})
+	page := filepath.Join(dir, "index.html")
+
+	// 修改文件并调整修改时间，避免文件系统时间精度导致检测不到变化
+	update := func(content string, age time.Duration) {
+		if err := os.WriteFile(page, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Now().Add(age)
+		if err := os.Chtimes(page, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	release := NewDog()
+	release.LoadTemplate(filepath.Join(dir, "*.html"))
+	debug := NewDog()
+	debug.SetMode(DebugMode)
+	debug.LoadTemplate(filepath.Join(dir, "*.html"))
+	if err := debug.AddTemplateSet("set", TemplateSet{Page: page}); err != nil {
+		t.Fatal(err)
+	}
+
+	update("v2", time.Hour)
+	if got := renderTemplate(t, release, "index.html", nil); got != "v1" {
+		t.Errorf("release mode: got %q, want v1", got)
+	}
+	if got := renderTemplate(t, debug, "index.html", nil); got != "v2" {
+		t.Errorf("debug mode: got %q, want v2", got)
+	}
+	if got := renderTemplate(t, debug, "set", nil); got != "v2" {
+		t.Errorf("debug mode set: got %q, want v2", got)
+	}
+
+	// 解析错误输出到浏览器
+	update("{{.Broken", 2*time.Hour)
+	w := httptest.NewRecorder()
+	ctx := &Context{e: debug, w: w, r: httptest.NewRequest(http.MethodGet, "/", nil)}
+	if err := ctx.HTMLTemplate(http.StatusOK, "set", nil); err == nil {
+		t.Fatal("expected parse error")
+	}
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "Template Error") || !ctx.IsAborted() {
+		t.Errorf("status = %d, body = %q", w.Code, w.Body.String())
+	}
+
+	update("v3", 3*time.Hour)
+	if got := renderTemplate(t, debug, "set", nil); got != "v3" {
+		t.Errorf("after fix: got %q, want v3", got)
+	}
+}
+
+func TestDog_TemplateFS(t *testing.T) {
+	files := map[string]string{
+		"layout.html":       `<title>{{block "title" .}}default{{end}}</title>{{template "nav" .}}{{block "content" .}}{{end}}`,
+		"pages/home.html":   `{{define "title"}}Home{{end}}{{define "content"}}hello {{upper .}}{{end}}`,
+		"partials/nav.html": `{{define "nav"}}<nav/>{{end}}`,
+	}
+	mapFS := fstest.MapFS{}
+	for name, content := range files {
+		mapFS[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+
+	// 内嵌文件系统和磁盘目录的渲染结果相同
+	for name, fsys := range map[string]fs.FS{"map": mapFS, "dir": os.DirFS(writeTemplates(t, files))} {
+		dog := NewDog()
+		dog.SetFuncMap(template.FuncMap{"upper": strings.ToUpper})
+		dog.LoadTemplateFS(fsys, "pages/*.html", "partials/*.html")
+
+		err := dog.AddTemplateSet("home", TemplateSet{FS: fsys, Layout: "layout.html", Page: "pages/home.html", Partials: []string{"partials/*.html"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := renderTemplate(t, dog, "home", "dog"), "<title>Home</title><nav/>hello DOG"; got != want {
+			t.Errorf("%s: set got %q, want %q", name, got, want)
+		}
+		if got, want := renderTemplate(t, dog, "nav", nil), "<nav/>"; got != want {
+			t.Errorf("%s: flat got %q, want %q", name, got, want)
+		}
+	}
+}
+
+// 将模板名称和数据原样输出的引擎
+type echoEngine struct {
+	loads int
+}
+
+func (e *echoEngine) Load() error {
+	e.loads++
+	return nil
+}
+
+func (e *echoEngine) Render(w io.Writer, name string, data any) error {
+	_, err := fmt.Fprintf(w, "%s:%v", name, data)
+	return err
+}
+
+func TestDog_SetHTMLEngine(t *testing.T) {
+	dog := NewDog()
+	engine := &echoEngine{}
+	if err := dog.SetHTMLEngine(engine); err != nil {
+		t.Fatal(err)
+	}
+	if engine.loads != 1 {
+		t.Errorf("loads = %d, want 1", engine.loads)
+	}
+	if got, want := renderTemplate(t, dog, "page", 1), "page:1"; got != want {
+		t.Errorf("custom engine got %q, want %q", got, want)
+	}
+
+	// text/template不转义HTML
+	dir := writeTemplates(t, map[string]string{"page.html": `<b>{{.}}</b>`})
+	err := dog.SetHTMLEngine(&render.TextTemplateEngine{Patterns: []string{filepath.Join(dir, "*.html")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := renderTemplate(t, dog, "page.html", "<i>"), "<b><i></b>"; got != want {
+		t.Errorf("text engine got %q, want %q", got, want)
+	}
+
+	dog.LoadTemplate(filepath.Join(dir, "*.html"))
+	if got, want := renderTemplate(t, dog, "page.html", "<i>"), "<b>&lt;i&gt;</b>"; got != want {
+		t.Errorf("html engine got %q, want %q", got, want)
+	}
+}
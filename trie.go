@@ -78,3 +78,49 @@ func (tn *TrieNode) Search(path string) *TrieNode {
 	tn = root
 	return nil
 }
+
+// Param 路由参数
+type Param struct {
+	Key   string
+	Value string
+}
+
+// Params 路由参数列表
+type Params []Param
+
+// Get 获取路由参数
+func (ps Params) Get(key string) (string, bool) {
+	for _, p := range ps {
+		if p.Key == key {
+			return p.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// 根据路由模式从请求路径中提取参数，:name匹配单段路径，*name匹配剩余的全部路径
+func extractParams(pattern string, path string, params Params) Params {
+	patternParts := strings.Split(pattern, "/")
+	pathParts := strings.Split(path, "/")
+
+	for i, part := range patternParts {
+		if i >= len(pathParts) || part == "" {
+			continue
+		}
+
+		switch part[0] {
+		case ':':
+			params = append(params, Param{Key: part[1:], Value: pathParts[i]})
+		case '*':
+			key := part[1:]
+			if key == "" {
+				key = "*"
+			}
+			params = append(params, Param{Key: key, Value: strings.Join(pathParts[i:], "/")})
+			return params
+		}
+	}
+
+	return params
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"unicode"
@@ -40,6 +41,33 @@ func isASCII(str string) bool {
 	return true
 }
 
+// 计算上传文件的保存路径，拒绝包含..的路径
+func uploadFilePath(filename string, savePath string) (string, error) {
+	for _, part := range strings.FieldsFunc(savePath, isPathSeparator) {
+		if part == ".." {
+			return "", ErrUnsafeUploadPath
+		}
+	}
+
+	info, err := os.Stat(savePath)
+	isDir := err == nil && info.IsDir()
+	if !isDir && !strings.HasSuffix(savePath, "/") && !strings.HasSuffix(savePath, string(filepath.Separator)) {
+		return filepath.Clean(savePath), nil
+	}
+
+	// 只保留客户端文件名的最后一段，兼容Windows风格的路径
+	parts := strings.FieldsFunc(filename, isPathSeparator)
+	if len(parts) == 0 || parts[len(parts)-1] == ".." || parts[len(parts)-1] == "." {
+		return "", ErrUnsafeUploadPath
+	}
+
+	return filepath.Join(savePath, parts[len(parts)-1]), nil
+}
+
+func isPathSeparator(r rune) bool {
+	return r == '/' || r == '\\'
+}
+
 // 清除终端
 func clearTerminal() {
 	var cmd *exec.Cmd